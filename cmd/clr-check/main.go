@@ -0,0 +1,152 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// clr-check evaluates the Pods and workloads of manifests against the CustomLimitRanges and
+// ClusterCustomLimitRanges of the same manifests, as the PodAnnotator webhook would, and exits
+// non-zero when one of them, or one of the policies, would be denied.
+package main
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/jessevdk/go-flags"
+	log "github.com/sirupsen/logrus"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/offline"
+)
+
+// Exit codes.
+const (
+	exitViolations = 1
+	exitError      = 2
+)
+
+// description is the help text on what the check covers.
+const description = "Evaluates the Pods and the pod templates of the workloads of the manifests against the " +
+	"CustomLimitRanges and ClusterCustomLimitRanges of the same manifests, as the webhook would admit their pods. " +
+	"The policies are validated first: an invalid policy is reported as failed and bounds no pod.\n\n" +
+	"The check covers the kubernetes.io bandwidth annotations, the bandwidth of the Multus networks and the " +
+	"defaults injected. It does not translate the bandwidth to the annotations of the CNI backend of the " +
+	"namespace, nor add the provenance annotations of the webhook: the mutated manifests only carry the " +
+	"bandwidth annotations."
+
+var opts struct {
+	Namespace     string `long:"namespace" short:"n" default:"default" description:"The namespace of the objects that have none"`
+	Output        string `long:"output" short:"o" default:"text" choice:"text" choice:"json" choice:"junit" description:"The format of the report"`
+	Report        string `long:"report" short:"r" default:"-" description:"Path to the report file, - for stdout"`
+	Mutated       string `long:"mutated" short:"m" description:"Path to write the manifests with the mutated pod annotations, - for stdout"`
+	FailOnWarning bool   `long:"fail-on-warning" description:"Exit non-zero when an object is only admitted with warnings"`
+	Args          struct {
+		Paths []string `positional-arg-name:"PATH" description:"Manifest files or directories of .yaml, .yml and .json files, - for stdin (default)"`
+	} `positional-args:"yes"`
+}
+
+func main() {
+	parser := flags.NewParser(&opts, flags.Default)
+	parser.LongDescription = description
+	if _, err := parser.Parse(); err != nil {
+		if flags.WroteHelp(err) {
+			return
+		}
+		os.Exit(exitError)
+	}
+	// the CustomLimitRange defaulting logs through controller-runtime
+	logf.SetLogger(logr.Discard())
+
+	paths := opts.Args.Paths
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+	m := &offline.Manifests{}
+	for _, path := range paths {
+		if err := load(m, path); err != nil {
+			log.Errorf("Failed to load manifests: %s", err)
+			os.Exit(exitError)
+		}
+	}
+
+	report := m.Check(opts.Namespace)
+
+	if opts.Mutated != "" {
+		if err := write(opts.Mutated, func(w io.Writer) error {
+			_, err := m.WriteTo(w)
+			return err
+		}); err != nil {
+			log.Errorf("Failed to write the mutated manifests: %s", err)
+			os.Exit(exitError)
+		}
+	}
+	if err := write(opts.Report, func(w io.Writer) error {
+		return report.Write(w, opts.Output)
+	}); err != nil {
+		log.Errorf("Failed to write the report: %s", err)
+		os.Exit(exitError)
+	}
+
+	if report.Failing(opts.FailOnWarning) {
+		os.Exit(exitViolations)
+	}
+}
+
+// load reads the manifests of a file, of the manifest files of a directory, or of stdin.
+func load(m *offline.Manifests, path string) error {
+	if path == "-" {
+		return m.Load("stdin", os.Stdin, opts.Namespace)
+	}
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(p)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			if p != path {
+				return nil
+			}
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return m.Load(p, f, opts.Namespace)
+	})
+}
+
+// write calls fn with the file at path, or with stdout for -.
+func write(path string, fn func(io.Writer) error) error {
+	if path == "-" {
+		return fn(os.Stdout)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := fn(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
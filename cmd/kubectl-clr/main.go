@@ -0,0 +1,162 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-clr is a kubectl plugin explaining the bandwidth policies in effect:
+//
+//	kubectl clr explain pod/<name>   the policies of a pod and where its bandwidth annotations come from
+//	kubectl clr describe ns/<name>   the effective min, default and max of a namespace
+//	kubectl clr top                  the pods by configured bandwidth
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/go-logr/logr"
+	"github.com/jessevdk/go-flags"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/plugin"
+	customv1 "github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(customv1.AddToScheme(scheme))
+}
+
+var opts struct {
+	Kubeconfig string `long:"kubeconfig" description:"Path to the kubeconfig file, defaults to $KUBECONFIG or ~/.kube/config"`
+	Context    string `long:"context" description:"The kubeconfig context to use"`
+	Namespace  string `long:"namespace" short:"n" description:"The namespace, defaults to the one of the context"`
+}
+
+type explainCommand struct {
+	Args struct {
+		Pod string `positional-arg-name:"pod/NAME" required:"yes"`
+	} `positional-args:"yes"`
+}
+
+type describeCommand struct {
+	Labels string `long:"labels" short:"l" description:"Merge the range for the pods with these labels, k1=v1,k2=v2"`
+	Args   struct {
+		Namespace string `positional-arg-name:"ns/NAME"`
+	} `positional-args:"yes"`
+}
+
+type topCommand struct {
+	AllNamespaces bool `long:"all-namespaces" short:"A" description:"List the pods of all namespaces"`
+}
+
+func main() {
+	parser := flags.NewParser(&opts, flags.Default)
+	parser.Name = "kubectl-clr"
+	_, _ = parser.AddCommand("explain", "Explain the bandwidth of a pod",
+		"Show the policies in effect for a pod and whether its bandwidth annotations were set by the user, defaulted or clamped.", &explainCommand{})
+	_, _ = parser.AddCommand("describe", "Describe the bandwidth policies of a namespace",
+		"Show the policies in effect in a namespace and the effective min, default and max of every annotation.", &describeCommand{})
+	_, _ = parser.AddCommand("top", "List the pods by configured bandwidth",
+		"List the pods by descending configured bandwidth, ingress plus egress.", &topCommand{})
+
+	// the policy lookup logs through controller-runtime
+	logf.SetLogger(logr.Discard())
+
+	if _, err := parser.Parse(); err != nil {
+		if flags.WroteHelp(err) {
+			return
+		}
+		os.Exit(1)
+	}
+}
+
+// newClient returns a client of the cluster of the kubeconfig, and the namespace to use.
+func newClient() (client.Client, string, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = opts.Kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: opts.Context}
+	overrides.Context.Namespace = opts.Namespace
+	config := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+
+	namespace, _, err := config.Namespace()
+	if err != nil {
+		return nil, "", err
+	}
+	restConfig, err := config.ClientConfig()
+	if err != nil {
+		return nil, "", err
+	}
+	c, err := client.New(restConfig, client.Options{Scheme: scheme})
+	return c, namespace, err
+}
+
+func (cmd *explainCommand) Execute([]string) error {
+	name, err := plugin.ResourceName(cmd.Args.Pod, "pod", "pods", "po")
+	if err != nil {
+		return err
+	}
+	c, namespace, err := newClient()
+	if err != nil {
+		return err
+	}
+	explanation, err := plugin.ExplainPod(context.Background(), c, namespace, name)
+	if err != nil {
+		return err
+	}
+	return explanation.Print(os.Stdout)
+}
+
+func (cmd *describeCommand) Execute([]string) error {
+	podLabels, err := plugin.ParseLabels(cmd.Labels)
+	if err != nil {
+		return err
+	}
+	c, namespace, err := newClient()
+	if err != nil {
+		return err
+	}
+	if cmd.Args.Namespace != "" {
+		if namespace, err = plugin.ResourceName(cmd.Args.Namespace, "ns", "namespace", "namespaces"); err != nil {
+			return err
+		}
+	}
+	description, err := plugin.DescribeNamespace(context.Background(), c, namespace, podLabels)
+	if err != nil {
+		return err
+	}
+	return description.Print(os.Stdout)
+}
+
+func (cmd *topCommand) Execute([]string) error {
+	c, namespace, err := newClient()
+	if err != nil {
+		return err
+	}
+	if cmd.AllNamespaces {
+		namespace = ""
+	}
+	pods, err := plugin.Top(context.Background(), c, namespace)
+	if err != nil {
+		return err
+	}
+	return plugin.PrintTop(os.Stdout, pods, cmd.AllNamespaces)
+}
@@ -18,25 +18,31 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
-	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/flowcontrol"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/controller"
 	injector "github.com/kubeservice-stack/custom-limit-range/pkg/injector"
 	customv1 "github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+	customv2 "github.com/kubeservice-stack/custom-limit-range/pkg/webhook/v2"
 )
 
 var (
@@ -48,6 +54,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(customv1.AddToScheme(scheme))
+	utilruntime.Must(customv2.AddToScheme(scheme))
 }
 
 func main() {
@@ -55,9 +62,26 @@ func main() {
 	var enableLeaderElection bool
 	var probeAddr string
 	var certsDir string
+	var injectTemplateDefaults bool
+	var bandwidthBackend string
+	var remediatePods bool
+	var remediationRestartsPerHour int
+	var remediationCooldown time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.StringVar(&certsDir, "certs-directory", "/etc/webhook/certs", "The cert directory for https")
+	flag.BoolVar(&injectTemplateDefaults, "inject-template-defaults", false,
+		"Inject the bandwidth defaults into the pod templates of workloads, not only into their pods.")
+	flag.StringVar(&bandwidthBackend, "bandwidth-backend", injector.BackendBandwidth,
+		"The CNI backend translating the bandwidth annotations of the pods, one of "+strings.Join(injector.BackendNames(), ", ")+
+			". A namespace can select another one with the "+common.BandwidthBackendAnnotation+" annotation.")
+	flag.BoolVar(&remediatePods, "remediate-pods", false,
+		"Restart the Deployment or StatefulSet owning a pod out of compliance with the bandwidth policies, "+
+			"when the pods created from its template would comply. Otherwise such pods only get events.")
+	flag.IntVar(&remediationRestartsPerHour, "remediation-restarts-per-hour", 6,
+		"The maximum number of workloads restarted per hour by --remediate-pods.")
+	flag.DurationVar(&remediationCooldown, "remediation-cooldown", 30*time.Minute,
+		"The minimum time between two restarts of a workload by --remediate-pods.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -69,6 +93,15 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if _, err := injector.LookupBackend(bandwidthBackend); err != nil {
+		setupLog.Error(err, "invalid --bandwidth-backend")
+		os.Exit(1)
+	}
+	if remediationRestartsPerHour <= 0 {
+		setupLog.Error(fmt.Errorf("must be positive, got %d", remediationRestartsPerHour), "invalid --remediation-restarts-per-hour")
+		os.Exit(1)
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
 		Metrics: metricsserver.Options{
@@ -80,6 +113,7 @@ func main() {
 				Port:    9443,
 			}),
 		HealthProbeBindAddress: probeAddr,
+		Cache:                  injector.PolicyCacheOptions(),
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "28efb73e.cmss.com",
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
@@ -108,22 +142,80 @@ func main() {
 		os.Exit(1)
 	}
 
+	ctx := ctrl.SetupSignalHandler()
+	policyCacheSynced, err := injector.SetupPolicyCache(ctx, mgr.GetCache())
+	if err != nil {
+		setupLog.Error(err, "unable to set up policy cache")
+		os.Exit(1)
+	}
+	if err := mgr.AddReadyzCheck("policy-cache", policyCacheSynced); err != nil {
+		setupLog.Error(err, "unable to set up policy cache ready check")
+		os.Exit(1)
+	}
+
 	if err = (&customv1.CustomLimitRange{}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "CustomLimitRange")
 		os.Exit(1)
 	}
 
-	if err := builder.WebhookManagedBy(mgr).
-		For(&corev1.Pod{}).
-		WithCustomPath("/mutate").
-		WithDefaulter(&injector.PodAnnotator{Client: mgr.GetClient()}).
-		Complete(); err != nil {
-		setupLog.Error(err, "unable to create webhook", "webhook", "Pod")
+	if err = (&customv1.ClusterCustomLimitRange{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "ClusterCustomLimitRange")
+		os.Exit(1)
+	}
+
+	if err = (&customv1.BandwidthQuota{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "BandwidthQuota")
+		os.Exit(1)
+	}
+
+	mgr.GetWebhookServer().Register("/mutate", &webhook.Admission{
+		Handler: &injector.PodAnnotator{
+			Client:   mgr.GetCache(),
+			Decoder:  admission.NewDecoder(mgr.GetScheme()),
+			Recorder: mgr.GetEventRecorderFor("customlimitrange-injector"),
+			Backend:  bandwidthBackend,
+		},
+	})
+
+	mgr.GetWebhookServer().Register("/mutate-workloads", &webhook.Admission{
+		Handler: &injector.WorkloadAnnotator{
+			Client:         mgr.GetCache(),
+			Decoder:        admission.NewDecoder(mgr.GetScheme()),
+			Recorder:       mgr.GetEventRecorderFor("customlimitrange-injector"),
+			InjectDefaults: injectTemplateDefaults,
+		},
+	})
+
+	if err = (&controller.CustomLimitRangeReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CustomLimitRange")
+		os.Exit(1)
+	}
+
+	if err = (&controller.BandwidthQuotaReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BandwidthQuota")
+		os.Exit(1)
+	}
+
+	if err = (&controller.PodComplianceReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Recorder:  mgr.GetEventRecorderFor("customlimitrange-controller"),
+		Remediate: remediatePods,
+		Limiter:   flowcontrol.NewTokenBucketRateLimiter(float32(remediationRestartsPerHour)/3600, 1),
+		Cooldown:  remediationCooldown,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "PodCompliance")
 		os.Exit(1)
 	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
@@ -0,0 +1,84 @@
+#!/usr/bin/env bash
+
+# Copyright 2022 The KubeService-Stack Authors.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Regenerates the deepcopy functions and the CRDs of hack/deployment/crds from the API types in pkg/webhook,
+# and the clientset, listers, informers and apply configurations of pkg/client.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+ROOT=$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)
+CONTROLLER_GEN=${CONTROLLER_GEN:-"go run sigs.k8s.io/controller-tools/cmd/controller-gen@v0.18.0"}
+# CODE_GENERATOR_BIN may point at a directory holding prebuilt k8s.io/code-generator binaries.
+CODE_GENERATOR_BIN=${CODE_GENERATOR_BIN:-}
+MODULE=github.com/kubeservice-stack/custom-limit-range
+
+codegen() {
+  local tool=$1
+  shift
+  if [[ -n "${CODE_GENERATOR_BIN}" ]]; then
+    "${CODE_GENERATOR_BIN}/${tool}" --go-header-file hack/boilerplate.go.txt "$@"
+  else
+    go run "k8s.io/code-generator/cmd/${tool}@v0.35.4" --go-header-file hack/boilerplate.go.txt "$@"
+  fi
+}
+
+cd "${ROOT}"
+${CONTROLLER_GEN} object:headerFile=hack/boilerplate.go.txt paths=./pkg/webhook/...
+${CONTROLLER_GEN} crd:crdVersions=v1 paths=./pkg/webhook/... output:crd:artifacts:config=hack/deployment/crds
+
+# CustomLimitRange is served as v1 and v2 and stored as v2, the conversion between them is done by the /convert
+# webhook of the manager. controller-gen does not generate this part of the CRD: merge the annotations and the
+# spec of the patch into the generated CRD, so that applying it installs the storage version with its conversion.
+CRD=hack/deployment/crds/custom.cmss.com_customlimitranges.yaml
+awk '
+  FNR == NR {
+    if ($0 ~ /^#/ || $0 ~ /^  annotations:$/) next
+    if ($0 ~ /^[a-z]+:$/) { section = $0; next }
+    patch[section] = patch[section] $0 "\n"
+    next
+  }
+  { print }
+  $0 == "  annotations:" && !annotated { printf "%s", patch["metadata:"]; annotated = 1 }
+  $0 == "spec:" && !converted { printf "%s", patch["spec:"]; converted = 1 }
+' hack/deployment/crds/patches/customlimitranges-conversion.yaml "${CRD}" > "${CRD}.tmp"
+mv "${CRD}.tmp" "${CRD}"
+
+# The code generators expect the API packages under <group>/<version> import paths. Generate from a copy of
+# the types staged at such paths, then point the generated code back to pkg/webhook and pkg/webhook/v2.
+STAGING=hack/apis
+trap 'rm -rf "${ROOT}/${STAGING}"' EXIT
+rm -rf "${STAGING}" pkg/client/{applyconfiguration,clientset,informers,listers}
+mkdir -p "${STAGING}/custom/v1" "${STAGING}/custom/v2"
+cp pkg/webhook/doc.go pkg/webhook/*_types.go pkg/webhook/zz_generated.deepcopy.go "${STAGING}/custom/v1/"
+cp pkg/webhook/v2/doc.go pkg/webhook/v2/*_types.go pkg/webhook/v2/zz_generated.deepcopy.go "${STAGING}/custom/v2/"
+INPUTS=("${MODULE}/${STAGING}/custom/v1" "${MODULE}/${STAGING}/custom/v2")
+
+codegen applyconfiguration-gen \
+  --output-dir pkg/client/applyconfiguration --output-pkg "${MODULE}/pkg/client/applyconfiguration" "${INPUTS[@]}"
+codegen client-gen --input-base "${MODULE}/${STAGING}" --input custom/v1 --input custom/v2 --clientset-name versioned \
+  --apply-configuration-package "${MODULE}/pkg/client/applyconfiguration" \
+  --output-dir pkg/client/clientset --output-pkg "${MODULE}/pkg/client/clientset"
+codegen lister-gen --output-dir pkg/client/listers --output-pkg "${MODULE}/pkg/client/listers" "${INPUTS[@]}"
+codegen informer-gen --versioned-clientset-package "${MODULE}/pkg/client/clientset/versioned" \
+  --listers-package "${MODULE}/pkg/client/listers" \
+  --output-dir pkg/client/informers --output-pkg "${MODULE}/pkg/client/informers" "${INPUTS[@]}"
+
+find pkg/client -name '*.go' -exec sed -i \
+  -e "s|${MODULE}/${STAGING}/custom/v1\"|${MODULE}/pkg/webhook\"|" \
+  -e "s|${MODULE}/${STAGING}/custom/v2\"|${MODULE}/pkg/webhook/v2\"|" {} +
+gofmt -w pkg/client
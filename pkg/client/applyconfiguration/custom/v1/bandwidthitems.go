@@ -0,0 +1,55 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	resource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+// BandwidthItemsApplyConfiguration represents a declarative configuration of the BandwidthItems type for use
+// with apply.
+//
+// BandwidthItems is a total ingress/egress bandwidth. Unlike CustomItems, it is not range validated,
+// as the sum of the pod bandwidth may be out of the range of a single policy.
+type BandwidthItemsApplyConfiguration struct {
+	Ingress *resource.Quantity `json:"ingress-bandwidth,omitempty"`
+	Egress  *resource.Quantity `json:"egress-bandwidth,omitempty"`
+}
+
+// BandwidthItemsApplyConfiguration constructs a declarative configuration of the BandwidthItems type for use with
+// apply.
+func BandwidthItems() *BandwidthItemsApplyConfiguration {
+	return &BandwidthItemsApplyConfiguration{}
+}
+
+// WithIngress sets the Ingress field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Ingress field is set to the value of the last call.
+func (b *BandwidthItemsApplyConfiguration) WithIngress(value resource.Quantity) *BandwidthItemsApplyConfiguration {
+	b.Ingress = &value
+	return b
+}
+
+// WithEgress sets the Egress field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Egress field is set to the value of the last call.
+func (b *BandwidthItemsApplyConfiguration) WithEgress(value resource.Quantity) *BandwidthItemsApplyConfiguration {
+	b.Egress = &value
+	return b
+}
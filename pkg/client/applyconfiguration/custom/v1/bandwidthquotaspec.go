@@ -0,0 +1,43 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+// BandwidthQuotaSpecApplyConfiguration represents a declarative configuration of the BandwidthQuotaSpec type for use
+// with apply.
+//
+// BandwidthQuotaSpec defines the desired state of BandwidthQuota
+type BandwidthQuotaSpecApplyConfiguration struct {
+	// Hard is the total ingress/egress bandwidth the pods of the namespace may claim. It does not
+	// accept bursts.
+	Hard *CustomItemsApplyConfiguration `json:"hard,omitempty"`
+}
+
+// BandwidthQuotaSpecApplyConfiguration constructs a declarative configuration of the BandwidthQuotaSpec type for use with
+// apply.
+func BandwidthQuotaSpec() *BandwidthQuotaSpecApplyConfiguration {
+	return &BandwidthQuotaSpecApplyConfiguration{}
+}
+
+// WithHard sets the Hard field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Hard field is set to the value of the last call.
+func (b *BandwidthQuotaSpecApplyConfiguration) WithHard(value *CustomItemsApplyConfiguration) *BandwidthQuotaSpecApplyConfiguration {
+	b.Hard = value
+	return b
+}
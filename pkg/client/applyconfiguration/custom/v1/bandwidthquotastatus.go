@@ -0,0 +1,52 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+// BandwidthQuotaStatusApplyConfiguration represents a declarative configuration of the BandwidthQuotaStatus type for use
+// with apply.
+//
+// BandwidthQuotaStatus defines the observed state of BandwidthQuota
+type BandwidthQuotaStatusApplyConfiguration struct {
+	// Hard is the enforced hard limits.
+	Hard *BandwidthItemsApplyConfiguration `json:"hard,omitempty"`
+	// Used is the bandwidth currently claimed by the pods of the namespace.
+	Used *BandwidthItemsApplyConfiguration `json:"used,omitempty"`
+}
+
+// BandwidthQuotaStatusApplyConfiguration constructs a declarative configuration of the BandwidthQuotaStatus type for use with
+// apply.
+func BandwidthQuotaStatus() *BandwidthQuotaStatusApplyConfiguration {
+	return &BandwidthQuotaStatusApplyConfiguration{}
+}
+
+// WithHard sets the Hard field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Hard field is set to the value of the last call.
+func (b *BandwidthQuotaStatusApplyConfiguration) WithHard(value *BandwidthItemsApplyConfiguration) *BandwidthQuotaStatusApplyConfiguration {
+	b.Hard = value
+	return b
+}
+
+// WithUsed sets the Used field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Used field is set to the value of the last call.
+func (b *BandwidthQuotaStatusApplyConfiguration) WithUsed(value *BandwidthItemsApplyConfiguration) *BandwidthQuotaStatusApplyConfiguration {
+	b.Used = value
+	return b
+}
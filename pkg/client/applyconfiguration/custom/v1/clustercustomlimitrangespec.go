@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	customv1 "github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+	metav1 "k8s.io/client-go/applyconfigurations/meta/v1"
+)
+
+// ClusterCustomLimitRangeSpecApplyConfiguration represents a declarative configuration of the ClusterCustomLimitRangeSpec type for use
+// with apply.
+//
+// ClusterCustomLimitRangeSpec defines the desired state of ClusterCustomLimitRange
+type ClusterCustomLimitRangeSpecApplyConfiguration struct {
+	// NamespaceSelector selects the namespaces the policy applies to.
+	// A nil selector selects every namespace.
+	NamespaceSelector *metav1.LabelSelectorApplyConfiguration `json:"namespaceSelector,omitempty"`
+	LRange            *LimitRangeApplyConfiguration           `json:"limitrange,omitempty"`
+	// EnforcementMode is one of enforce (default), clamp, warn, audit or dryRun.
+	EnforcementMode *customv1.EnforcementMode `json:"enforcementMode,omitempty"`
+}
+
+// ClusterCustomLimitRangeSpecApplyConfiguration constructs a declarative configuration of the ClusterCustomLimitRangeSpec type for use with
+// apply.
+func ClusterCustomLimitRangeSpec() *ClusterCustomLimitRangeSpecApplyConfiguration {
+	return &ClusterCustomLimitRangeSpecApplyConfiguration{}
+}
+
+// WithNamespaceSelector sets the NamespaceSelector field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the NamespaceSelector field is set to the value of the last call.
+func (b *ClusterCustomLimitRangeSpecApplyConfiguration) WithNamespaceSelector(value *metav1.LabelSelectorApplyConfiguration) *ClusterCustomLimitRangeSpecApplyConfiguration {
+	b.NamespaceSelector = value
+	return b
+}
+
+// WithLRange sets the LRange field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LRange field is set to the value of the last call.
+func (b *ClusterCustomLimitRangeSpecApplyConfiguration) WithLRange(value *LimitRangeApplyConfiguration) *ClusterCustomLimitRangeSpecApplyConfiguration {
+	b.LRange = value
+	return b
+}
+
+// WithEnforcementMode sets the EnforcementMode field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the EnforcementMode field is set to the value of the last call.
+func (b *ClusterCustomLimitRangeSpecApplyConfiguration) WithEnforcementMode(value customv1.EnforcementMode) *ClusterCustomLimitRangeSpecApplyConfiguration {
+	b.EnforcementMode = &value
+	return b
+}
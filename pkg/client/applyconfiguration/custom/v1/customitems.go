@@ -0,0 +1,78 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	resource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+// CustomItemsApplyConfiguration represents a declarative configuration of the CustomItems type for use
+// with apply.
+//
+// The fields are omitzero rather than omitempty, which does not apply to structs, so that unset
+// quantities are not serialized as "0" by the defaulting webhook. The validation rules mirror
+// validateItems so that invalid policies are rejected even when the webhook is down.
+// CustomItems holds ingress/egress bandwidth rates and token bucket sizes, in bits.
+type CustomItemsApplyConfiguration struct {
+	Ingress *resource.Quantity `json:"ingress-bandwidth,omitempty"`
+	Egress  *resource.Quantity `json:"egress-bandwidth,omitempty"`
+	// IngressBurst and EgressBurst are the token bucket sizes, in bits, of the bandwidth plugin. They
+	// only bound Multus networks, in spec.networks: the container runtimes hard-code the burst of
+	// the pod network.
+	IngressBurst *resource.Quantity `json:"ingress-burst,omitempty"`
+	EgressBurst  *resource.Quantity `json:"egress-burst,omitempty"`
+}
+
+// CustomItemsApplyConfiguration constructs a declarative configuration of the CustomItems type for use with
+// apply.
+func CustomItems() *CustomItemsApplyConfiguration {
+	return &CustomItemsApplyConfiguration{}
+}
+
+// WithIngress sets the Ingress field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Ingress field is set to the value of the last call.
+func (b *CustomItemsApplyConfiguration) WithIngress(value resource.Quantity) *CustomItemsApplyConfiguration {
+	b.Ingress = &value
+	return b
+}
+
+// WithEgress sets the Egress field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Egress field is set to the value of the last call.
+func (b *CustomItemsApplyConfiguration) WithEgress(value resource.Quantity) *CustomItemsApplyConfiguration {
+	b.Egress = &value
+	return b
+}
+
+// WithIngressBurst sets the IngressBurst field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the IngressBurst field is set to the value of the last call.
+func (b *CustomItemsApplyConfiguration) WithIngressBurst(value resource.Quantity) *CustomItemsApplyConfiguration {
+	b.IngressBurst = &value
+	return b
+}
+
+// WithEgressBurst sets the EgressBurst field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the EgressBurst field is set to the value of the last call.
+func (b *CustomItemsApplyConfiguration) WithEgressBurst(value resource.Quantity) *CustomItemsApplyConfiguration {
+	b.EgressBurst = &value
+	return b
+}
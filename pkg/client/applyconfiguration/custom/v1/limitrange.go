@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+// LimitRangeApplyConfiguration represents a declarative configuration of the LimitRange type for use
+// with apply.
+//
+// LimitRange bounds the bandwidth of pods. It takes no bursts, see CustomItems.
+type LimitRangeApplyConfiguration struct {
+	Type                             *string `json:"type,omitempty"`
+	BandwidthRangeApplyConfiguration `json:",inline"`
+}
+
+// LimitRangeApplyConfiguration constructs a declarative configuration of the LimitRange type for use with
+// apply.
+func LimitRange() *LimitRangeApplyConfiguration {
+	return &LimitRangeApplyConfiguration{}
+}
+
+// WithType sets the Type field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Type field is set to the value of the last call.
+func (b *LimitRangeApplyConfiguration) WithType(value string) *LimitRangeApplyConfiguration {
+	b.Type = &value
+	return b
+}
+
+// WithMax sets the Max field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Max field is set to the value of the last call.
+func (b *LimitRangeApplyConfiguration) WithMax(value *CustomItemsApplyConfiguration) *LimitRangeApplyConfiguration {
+	b.BandwidthRangeApplyConfiguration.Max = value
+	return b
+}
+
+// WithMin sets the Min field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Min field is set to the value of the last call.
+func (b *LimitRangeApplyConfiguration) WithMin(value *CustomItemsApplyConfiguration) *LimitRangeApplyConfiguration {
+	b.BandwidthRangeApplyConfiguration.Min = value
+	return b
+}
+
+// WithDefault sets the Default field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Default field is set to the value of the last call.
+func (b *LimitRangeApplyConfiguration) WithDefault(value *CustomItemsApplyConfiguration) *LimitRangeApplyConfiguration {
+	b.BandwidthRangeApplyConfiguration.Default = value
+	return b
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1
+
+// NetworkLimitRangeApplyConfiguration represents a declarative configuration of the NetworkLimitRange type for use
+// with apply.
+//
+// NetworkLimitRange bounds the bandwidth of the interfaces that pods attach to a Multus secondary
+// network, in the bandwidth of their network selection in the k8s.v1.cni.cncf.io/networks annotation.
+type NetworkLimitRangeApplyConfiguration struct {
+	// Name is the NetworkAttachmentDefinition, as namespace/name, or as name in the namespace of
+	// the CustomLimitRange.
+	Name                             *string `json:"name,omitempty"`
+	BandwidthRangeApplyConfiguration `json:",inline"`
+}
+
+// NetworkLimitRangeApplyConfiguration constructs a declarative configuration of the NetworkLimitRange type for use with
+// apply.
+func NetworkLimitRange() *NetworkLimitRangeApplyConfiguration {
+	return &NetworkLimitRangeApplyConfiguration{}
+}
+
+// WithName sets the Name field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Name field is set to the value of the last call.
+func (b *NetworkLimitRangeApplyConfiguration) WithName(value string) *NetworkLimitRangeApplyConfiguration {
+	b.Name = &value
+	return b
+}
+
+// WithMax sets the Max field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Max field is set to the value of the last call.
+func (b *NetworkLimitRangeApplyConfiguration) WithMax(value *CustomItemsApplyConfiguration) *NetworkLimitRangeApplyConfiguration {
+	b.BandwidthRangeApplyConfiguration.Max = value
+	return b
+}
+
+// WithMin sets the Min field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Min field is set to the value of the last call.
+func (b *NetworkLimitRangeApplyConfiguration) WithMin(value *CustomItemsApplyConfiguration) *NetworkLimitRangeApplyConfiguration {
+	b.BandwidthRangeApplyConfiguration.Min = value
+	return b
+}
+
+// WithDefault sets the Default field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Default field is set to the value of the last call.
+func (b *NetworkLimitRangeApplyConfiguration) WithDefault(value *CustomItemsApplyConfiguration) *NetworkLimitRangeApplyConfiguration {
+	b.BandwidthRangeApplyConfiguration.Default = value
+	return b
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v2
+
+// BandwidthRangeApplyConfiguration represents a declarative configuration of the BandwidthRange type for use
+// with apply.
+//
+// BandwidthRange is the max, min and default of a bandwidth range. Its validation rules require
+// min <= default <= max.
+type BandwidthRangeApplyConfiguration struct {
+	Max     *LimitsApplyConfiguration `json:"max,omitempty"`
+	Min     *LimitsApplyConfiguration `json:"min,omitempty"`
+	Default *LimitsApplyConfiguration `json:"default,omitempty"`
+}
+
+// BandwidthRangeApplyConfiguration constructs a declarative configuration of the BandwidthRange type for use with
+// apply.
+func BandwidthRange() *BandwidthRangeApplyConfiguration {
+	return &BandwidthRangeApplyConfiguration{}
+}
+
+// WithMax sets the Max field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Max field is set to the value of the last call.
+func (b *BandwidthRangeApplyConfiguration) WithMax(value *LimitsApplyConfiguration) *BandwidthRangeApplyConfiguration {
+	b.Max = value
+	return b
+}
+
+// WithMin sets the Min field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Min field is set to the value of the last call.
+func (b *BandwidthRangeApplyConfiguration) WithMin(value *LimitsApplyConfiguration) *BandwidthRangeApplyConfiguration {
+	b.Min = value
+	return b
+}
+
+// WithDefault sets the Default field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Default field is set to the value of the last call.
+func (b *BandwidthRangeApplyConfiguration) WithDefault(value *LimitsApplyConfiguration) *BandwidthRangeApplyConfiguration {
+	b.Default = value
+	return b
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	customv2 "github.com/kubeservice-stack/custom-limit-range/pkg/webhook/v2"
+)
+
+// CustomLimitRangeSpecApplyConfiguration represents a declarative configuration of the CustomLimitRangeSpec type for use
+// with apply.
+//
+// CustomLimitRangeSpec defines the desired state of CustomLimitRange.
+// Its BandwidthRange is the catch-all range for pods not selected by any rule, which takes no bursts.
+type CustomLimitRangeSpecApplyConfiguration struct {
+	BandwidthRangeApplyConfiguration `json:",inline"`
+	// Rules are evaluated in order; the first rule selecting a pod replaces the catch-all range.
+	Rules []LimitRangeRuleApplyConfiguration `json:"rules,omitempty"`
+	// Networks bound the bandwidth of the interfaces of Multus secondary networks, by
+	// NetworkAttachmentDefinition. The catch-all range and the rules bound the cluster network.
+	Networks []NetworkLimitRangeApplyConfiguration `json:"networks,omitempty"`
+	// EnforcementMode is one of enforce (default), clamp, warn, audit or dryRun.
+	EnforcementMode *customv2.EnforcementMode `json:"enforcementMode,omitempty"`
+	// Priority orders the defaults of the CustomLimitRanges of a namespace, which may hold several of
+	// them; the highest priority wins, and two of them may not share a priority.
+	Priority *int32 `json:"priority,omitempty"`
+	// DefaultPolicy is one of none (default), min or max. Defaults it fills are listed in the
+	// customlimitrange.kubernetes.io/defaulted annotation.
+	DefaultPolicy *customv2.DefaultPolicy `json:"defaultPolicy,omitempty"`
+}
+
+// CustomLimitRangeSpecApplyConfiguration constructs a declarative configuration of the CustomLimitRangeSpec type for use with
+// apply.
+func CustomLimitRangeSpec() *CustomLimitRangeSpecApplyConfiguration {
+	return &CustomLimitRangeSpecApplyConfiguration{}
+}
+
+// WithMax sets the Max field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Max field is set to the value of the last call.
+func (b *CustomLimitRangeSpecApplyConfiguration) WithMax(value *LimitsApplyConfiguration) *CustomLimitRangeSpecApplyConfiguration {
+	b.BandwidthRangeApplyConfiguration.Max = value
+	return b
+}
+
+// WithMin sets the Min field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Min field is set to the value of the last call.
+func (b *CustomLimitRangeSpecApplyConfiguration) WithMin(value *LimitsApplyConfiguration) *CustomLimitRangeSpecApplyConfiguration {
+	b.BandwidthRangeApplyConfiguration.Min = value
+	return b
+}
+
+// WithDefault sets the Default field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Default field is set to the value of the last call.
+func (b *CustomLimitRangeSpecApplyConfiguration) WithDefault(value *LimitsApplyConfiguration) *CustomLimitRangeSpecApplyConfiguration {
+	b.BandwidthRangeApplyConfiguration.Default = value
+	return b
+}
+
+// WithRules adds the given value to the Rules field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Rules field.
+func (b *CustomLimitRangeSpecApplyConfiguration) WithRules(values ...*LimitRangeRuleApplyConfiguration) *CustomLimitRangeSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithRules")
+		}
+		b.Rules = append(b.Rules, *values[i])
+	}
+	return b
+}
+
+// WithNetworks adds the given value to the Networks field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Networks field.
+func (b *CustomLimitRangeSpecApplyConfiguration) WithNetworks(values ...*NetworkLimitRangeApplyConfiguration) *CustomLimitRangeSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithNetworks")
+		}
+		b.Networks = append(b.Networks, *values[i])
+	}
+	return b
+}
+
+// WithEnforcementMode sets the EnforcementMode field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the EnforcementMode field is set to the value of the last call.
+func (b *CustomLimitRangeSpecApplyConfiguration) WithEnforcementMode(value customv2.EnforcementMode) *CustomLimitRangeSpecApplyConfiguration {
+	b.EnforcementMode = &value
+	return b
+}
+
+// WithPriority sets the Priority field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Priority field is set to the value of the last call.
+func (b *CustomLimitRangeSpecApplyConfiguration) WithPriority(value int32) *CustomLimitRangeSpecApplyConfiguration {
+	b.Priority = &value
+	return b
+}
+
+// WithDefaultPolicy sets the DefaultPolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DefaultPolicy field is set to the value of the last call.
+func (b *CustomLimitRangeSpecApplyConfiguration) WithDefaultPolicy(value customv2.DefaultPolicy) *CustomLimitRangeSpecApplyConfiguration {
+	b.DefaultPolicy = &value
+	return b
+}
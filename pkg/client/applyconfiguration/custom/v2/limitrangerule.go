@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	v1 "k8s.io/client-go/applyconfigurations/meta/v1"
+)
+
+// LimitRangeRuleApplyConfiguration represents a declarative configuration of the LimitRangeRule type for use
+// with apply.
+//
+// LimitRangeRule applies its own range to the pods selected by PodSelector. It takes no bursts.
+type LimitRangeRuleApplyConfiguration struct {
+	Name *string `json:"name,omitempty"`
+	// PodSelector selects the pods the rule applies to. A nil selector selects every pod.
+	PodSelector                      *v1.LabelSelectorApplyConfiguration `json:"podSelector,omitempty"`
+	BandwidthRangeApplyConfiguration `json:",inline"`
+}
+
+// LimitRangeRuleApplyConfiguration constructs a declarative configuration of the LimitRangeRule type for use with
+// apply.
+func LimitRangeRule() *LimitRangeRuleApplyConfiguration {
+	return &LimitRangeRuleApplyConfiguration{}
+}
+
+// WithName sets the Name field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Name field is set to the value of the last call.
+func (b *LimitRangeRuleApplyConfiguration) WithName(value string) *LimitRangeRuleApplyConfiguration {
+	b.Name = &value
+	return b
+}
+
+// WithPodSelector sets the PodSelector field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PodSelector field is set to the value of the last call.
+func (b *LimitRangeRuleApplyConfiguration) WithPodSelector(value *v1.LabelSelectorApplyConfiguration) *LimitRangeRuleApplyConfiguration {
+	b.PodSelector = value
+	return b
+}
+
+// WithMax sets the Max field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Max field is set to the value of the last call.
+func (b *LimitRangeRuleApplyConfiguration) WithMax(value *LimitsApplyConfiguration) *LimitRangeRuleApplyConfiguration {
+	b.BandwidthRangeApplyConfiguration.Max = value
+	return b
+}
+
+// WithMin sets the Min field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Min field is set to the value of the last call.
+func (b *LimitRangeRuleApplyConfiguration) WithMin(value *LimitsApplyConfiguration) *LimitRangeRuleApplyConfiguration {
+	b.BandwidthRangeApplyConfiguration.Min = value
+	return b
+}
+
+// WithDefault sets the Default field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Default field is set to the value of the last call.
+func (b *LimitRangeRuleApplyConfiguration) WithDefault(value *LimitsApplyConfiguration) *LimitRangeRuleApplyConfiguration {
+	b.BandwidthRangeApplyConfiguration.Default = value
+	return b
+}
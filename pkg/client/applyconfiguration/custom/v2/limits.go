@@ -0,0 +1,50 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v2
+
+// LimitsApplyConfiguration represents a declarative configuration of the Limits type for use
+// with apply.
+//
+// Limits holds one bound of the pod bandwidth in each direction.
+type LimitsApplyConfiguration struct {
+	Ingress *BandwidthApplyConfiguration `json:"ingress,omitempty"`
+	Egress  *BandwidthApplyConfiguration `json:"egress,omitempty"`
+}
+
+// LimitsApplyConfiguration constructs a declarative configuration of the Limits type for use with
+// apply.
+func Limits() *LimitsApplyConfiguration {
+	return &LimitsApplyConfiguration{}
+}
+
+// WithIngress sets the Ingress field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Ingress field is set to the value of the last call.
+func (b *LimitsApplyConfiguration) WithIngress(value *BandwidthApplyConfiguration) *LimitsApplyConfiguration {
+	b.Ingress = value
+	return b
+}
+
+// WithEgress sets the Egress field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Egress field is set to the value of the last call.
+func (b *LimitsApplyConfiguration) WithEgress(value *BandwidthApplyConfiguration) *LimitsApplyConfiguration {
+	b.Egress = value
+	return b
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package applyconfiguration
+
+import (
+	customv1 "github.com/kubeservice-stack/custom-limit-range/pkg/client/applyconfiguration/custom/v1"
+	customv2 "github.com/kubeservice-stack/custom-limit-range/pkg/client/applyconfiguration/custom/v2"
+	internal "github.com/kubeservice-stack/custom-limit-range/pkg/client/applyconfiguration/internal"
+	v1 "github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+	v2 "github.com/kubeservice-stack/custom-limit-range/pkg/webhook/v2"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	managedfields "k8s.io/apimachinery/pkg/util/managedfields"
+)
+
+// ForKind returns an apply configuration type for the given GroupVersionKind, or nil if no
+// apply configuration type exists for the given GroupVersionKind.
+func ForKind(kind schema.GroupVersionKind) interface{} {
+	switch kind {
+	// Group=custom.cmss.com, Version=v1
+	case v1.SchemeGroupVersion.WithKind("BandwidthItems"):
+		return &customv1.BandwidthItemsApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("BandwidthQuota"):
+		return &customv1.BandwidthQuotaApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("BandwidthQuotaSpec"):
+		return &customv1.BandwidthQuotaSpecApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("BandwidthQuotaStatus"):
+		return &customv1.BandwidthQuotaStatusApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("BandwidthRange"):
+		return &customv1.BandwidthRangeApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("ClusterCustomLimitRange"):
+		return &customv1.ClusterCustomLimitRangeApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("ClusterCustomLimitRangeSpec"):
+		return &customv1.ClusterCustomLimitRangeSpecApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("CustomItems"):
+		return &customv1.CustomItemsApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("CustomLimitRange"):
+		return &customv1.CustomLimitRangeApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("CustomLimitRangeSpec"):
+		return &customv1.CustomLimitRangeSpecApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("CustomLimitRangeStatus"):
+		return &customv1.CustomLimitRangeStatusApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("LimitRange"):
+		return &customv1.LimitRangeApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("LimitRangeRule"):
+		return &customv1.LimitRangeRuleApplyConfiguration{}
+	case v1.SchemeGroupVersion.WithKind("NetworkLimitRange"):
+		return &customv1.NetworkLimitRangeApplyConfiguration{}
+
+		// Group=custom.cmss.com, Version=v2
+	case v2.SchemeGroupVersion.WithKind("Bandwidth"):
+		return &customv2.BandwidthApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("BandwidthRange"):
+		return &customv2.BandwidthRangeApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("CustomLimitRange"):
+		return &customv2.CustomLimitRangeApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("CustomLimitRangeSpec"):
+		return &customv2.CustomLimitRangeSpecApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("CustomLimitRangeStatus"):
+		return &customv2.CustomLimitRangeStatusApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("LimitRangeRule"):
+		return &customv2.LimitRangeRuleApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("Limits"):
+		return &customv2.LimitsApplyConfiguration{}
+	case v2.SchemeGroupVersion.WithKind("NetworkLimitRange"):
+		return &customv2.NetworkLimitRangeApplyConfiguration{}
+
+	}
+	return nil
+}
+
+func NewTypeConverter(scheme *runtime.Scheme) managedfields.TypeConverter {
+	return managedfields.NewSchemeTypeConverter(scheme, internal.Parser())
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	customv1 "github.com/kubeservice-stack/custom-limit-range/pkg/client/applyconfiguration/custom/v1"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/client/clientset/versioned/fake"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/client/informers/externalversions"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+	webhookv2 "github.com/kubeservice-stack/custom-limit-range/pkg/webhook/v2"
+)
+
+func TestFakeClientset(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	clr := &webhook.CustomLimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "clr", Namespace: "default"},
+		Spec: webhook.CustomLimitRangeSpec{
+			LRange: webhook.LimitRange{Type: webhook.LimitRangeTypePod},
+		},
+	}
+	client := fake.NewSimpleClientset(clr)
+
+	got, err := client.CustomV1().CustomLimitRanges("default").Get(ctx, "clr", metav1.GetOptions{})
+	assert.Nil(err)
+	assert.Equal(clr.Spec, got.Spec)
+
+	_, err = client.CustomV1().ClusterCustomLimitRanges().Create(ctx, &webhook.ClusterCustomLimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "cclr"},
+	}, metav1.CreateOptions{})
+	assert.Nil(err)
+	_, err = client.CustomV2().CustomLimitRanges("other").Create(ctx, &webhookv2.CustomLimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "clr"},
+	}, metav1.CreateOptions{})
+	assert.Nil(err)
+
+	factory := externalversions.NewSharedInformerFactory(client, 0)
+	lister := factory.Custom().V1().CustomLimitRanges().Lister()
+	cclrLister := factory.Custom().V1().ClusterCustomLimitRanges().Lister()
+	factory.Start(ctx.Done())
+	for typ, synced := range factory.WaitForCacheSync(ctx.Done()) {
+		assert.True(synced, typ.String())
+	}
+
+	got, err = lister.CustomLimitRanges("default").Get("clr")
+	assert.Nil(err)
+	assert.Equal(clr.Spec, got.Spec)
+	_, err = cclrLister.Get("cclr")
+	assert.Nil(err)
+
+	// the informer follows later changes
+	_, err = client.CustomV1().CustomLimitRanges("default").Create(ctx, &webhook.CustomLimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "clr2", Namespace: "default"},
+	}, metav1.CreateOptions{})
+	assert.Nil(err)
+	assert.Eventually(func() bool {
+		_, err := lister.CustomLimitRanges("default").Get("clr2")
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestApplyConfiguration(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	clr := customv1.CustomLimitRange("clr", "default").
+		WithSpec(customv1.CustomLimitRangeSpec().
+			WithLRange(customv1.LimitRange().
+				WithMax(customv1.CustomItems().WithIngress(resource.MustParse("1G")))))
+	assert.Equal("CustomLimitRange", *clr.Kind)
+	assert.Equal(webhook.GroupVersion.String(), *clr.APIVersion)
+	assert.Equal("1G", clr.Spec.LRange.Max.Ingress.String())
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	context "context"
+
+	applyconfigurationcustomv1 "github.com/kubeservice-stack/custom-limit-range/pkg/client/applyconfiguration/custom/v1"
+	scheme "github.com/kubeservice-stack/custom-limit-range/pkg/client/clientset/versioned/scheme"
+	customv1 "github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// ClusterCustomLimitRangesGetter has a method to return a ClusterCustomLimitRangeInterface.
+// A group's client should implement this interface.
+type ClusterCustomLimitRangesGetter interface {
+	ClusterCustomLimitRanges() ClusterCustomLimitRangeInterface
+}
+
+// ClusterCustomLimitRangeInterface has methods to work with ClusterCustomLimitRange resources.
+type ClusterCustomLimitRangeInterface interface {
+	Create(ctx context.Context, clusterCustomLimitRange *customv1.ClusterCustomLimitRange, opts metav1.CreateOptions) (*customv1.ClusterCustomLimitRange, error)
+	Update(ctx context.Context, clusterCustomLimitRange *customv1.ClusterCustomLimitRange, opts metav1.UpdateOptions) (*customv1.ClusterCustomLimitRange, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*customv1.ClusterCustomLimitRange, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*customv1.ClusterCustomLimitRangeList, error)
+	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (result *customv1.ClusterCustomLimitRange, err error)
+	Apply(ctx context.Context, clusterCustomLimitRange *applyconfigurationcustomv1.ClusterCustomLimitRangeApplyConfiguration, opts metav1.ApplyOptions) (result *customv1.ClusterCustomLimitRange, err error)
+	ClusterCustomLimitRangeExpansion
+}
+
+// clusterCustomLimitRanges implements ClusterCustomLimitRangeInterface
+type clusterCustomLimitRanges struct {
+	*gentype.ClientWithListAndApply[*customv1.ClusterCustomLimitRange, *customv1.ClusterCustomLimitRangeList, *applyconfigurationcustomv1.ClusterCustomLimitRangeApplyConfiguration]
+}
+
+// newClusterCustomLimitRanges returns a ClusterCustomLimitRanges
+func newClusterCustomLimitRanges(c *CustomV1Client) *clusterCustomLimitRanges {
+	return &clusterCustomLimitRanges{
+		gentype.NewClientWithListAndApply[*customv1.ClusterCustomLimitRange, *customv1.ClusterCustomLimitRangeList, *applyconfigurationcustomv1.ClusterCustomLimitRangeApplyConfiguration](
+			"clustercustomlimitranges",
+			c.RESTClient(),
+			scheme.ParameterCodec,
+			"",
+			func() *customv1.ClusterCustomLimitRange { return &customv1.ClusterCustomLimitRange{} },
+			func() *customv1.ClusterCustomLimitRangeList { return &customv1.ClusterCustomLimitRangeList{} },
+		),
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	context "context"
+
+	applyconfigurationcustomv1 "github.com/kubeservice-stack/custom-limit-range/pkg/client/applyconfiguration/custom/v1"
+	scheme "github.com/kubeservice-stack/custom-limit-range/pkg/client/clientset/versioned/scheme"
+	customv1 "github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// CustomLimitRangesGetter has a method to return a CustomLimitRangeInterface.
+// A group's client should implement this interface.
+type CustomLimitRangesGetter interface {
+	CustomLimitRanges(namespace string) CustomLimitRangeInterface
+}
+
+// CustomLimitRangeInterface has methods to work with CustomLimitRange resources.
+type CustomLimitRangeInterface interface {
+	Create(ctx context.Context, customLimitRange *customv1.CustomLimitRange, opts metav1.CreateOptions) (*customv1.CustomLimitRange, error)
+	Update(ctx context.Context, customLimitRange *customv1.CustomLimitRange, opts metav1.UpdateOptions) (*customv1.CustomLimitRange, error)
+	// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+	UpdateStatus(ctx context.Context, customLimitRange *customv1.CustomLimitRange, opts metav1.UpdateOptions) (*customv1.CustomLimitRange, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*customv1.CustomLimitRange, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*customv1.CustomLimitRangeList, error)
+	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (result *customv1.CustomLimitRange, err error)
+	Apply(ctx context.Context, customLimitRange *applyconfigurationcustomv1.CustomLimitRangeApplyConfiguration, opts metav1.ApplyOptions) (result *customv1.CustomLimitRange, err error)
+	// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+	ApplyStatus(ctx context.Context, customLimitRange *applyconfigurationcustomv1.CustomLimitRangeApplyConfiguration, opts metav1.ApplyOptions) (result *customv1.CustomLimitRange, err error)
+	CustomLimitRangeExpansion
+}
+
+// customLimitRanges implements CustomLimitRangeInterface
+type customLimitRanges struct {
+	*gentype.ClientWithListAndApply[*customv1.CustomLimitRange, *customv1.CustomLimitRangeList, *applyconfigurationcustomv1.CustomLimitRangeApplyConfiguration]
+}
+
+// newCustomLimitRanges returns a CustomLimitRanges
+func newCustomLimitRanges(c *CustomV1Client, namespace string) *customLimitRanges {
+	return &customLimitRanges{
+		gentype.NewClientWithListAndApply[*customv1.CustomLimitRange, *customv1.CustomLimitRangeList, *applyconfigurationcustomv1.CustomLimitRangeApplyConfiguration](
+			"customlimitranges",
+			c.RESTClient(),
+			scheme.ParameterCodec,
+			namespace,
+			func() *customv1.CustomLimitRange { return &customv1.CustomLimitRange{} },
+			func() *customv1.CustomLimitRangeList { return &customv1.CustomLimitRangeList{} },
+		),
+	}
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	customv1 "github.com/kubeservice-stack/custom-limit-range/pkg/client/applyconfiguration/custom/v1"
+	typedcustomv1 "github.com/kubeservice-stack/custom-limit-range/pkg/client/clientset/versioned/typed/custom/v1"
+	v1 "github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// fakeBandwidthQuotas implements BandwidthQuotaInterface
+type fakeBandwidthQuotas struct {
+	*gentype.FakeClientWithListAndApply[*v1.BandwidthQuota, *v1.BandwidthQuotaList, *customv1.BandwidthQuotaApplyConfiguration]
+	Fake *FakeCustomV1
+}
+
+func newFakeBandwidthQuotas(fake *FakeCustomV1, namespace string) typedcustomv1.BandwidthQuotaInterface {
+	return &fakeBandwidthQuotas{
+		gentype.NewFakeClientWithListAndApply[*v1.BandwidthQuota, *v1.BandwidthQuotaList, *customv1.BandwidthQuotaApplyConfiguration](
+			fake.Fake,
+			namespace,
+			v1.SchemeGroupVersion.WithResource("bandwidthquotas"),
+			v1.SchemeGroupVersion.WithKind("BandwidthQuota"),
+			func() *v1.BandwidthQuota { return &v1.BandwidthQuota{} },
+			func() *v1.BandwidthQuotaList { return &v1.BandwidthQuotaList{} },
+			func(dst, src *v1.BandwidthQuotaList) { dst.ListMeta = src.ListMeta },
+			func(list *v1.BandwidthQuotaList) []*v1.BandwidthQuota { return gentype.ToPointerSlice(list.Items) },
+			func(list *v1.BandwidthQuotaList, items []*v1.BandwidthQuota) {
+				list.Items = gentype.FromPointerSlice(items)
+			},
+		),
+		fake,
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	customv1 "github.com/kubeservice-stack/custom-limit-range/pkg/client/applyconfiguration/custom/v1"
+	typedcustomv1 "github.com/kubeservice-stack/custom-limit-range/pkg/client/clientset/versioned/typed/custom/v1"
+	v1 "github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// fakeClusterCustomLimitRanges implements ClusterCustomLimitRangeInterface
+type fakeClusterCustomLimitRanges struct {
+	*gentype.FakeClientWithListAndApply[*v1.ClusterCustomLimitRange, *v1.ClusterCustomLimitRangeList, *customv1.ClusterCustomLimitRangeApplyConfiguration]
+	Fake *FakeCustomV1
+}
+
+func newFakeClusterCustomLimitRanges(fake *FakeCustomV1) typedcustomv1.ClusterCustomLimitRangeInterface {
+	return &fakeClusterCustomLimitRanges{
+		gentype.NewFakeClientWithListAndApply[*v1.ClusterCustomLimitRange, *v1.ClusterCustomLimitRangeList, *customv1.ClusterCustomLimitRangeApplyConfiguration](
+			fake.Fake,
+			"",
+			v1.SchemeGroupVersion.WithResource("clustercustomlimitranges"),
+			v1.SchemeGroupVersion.WithKind("ClusterCustomLimitRange"),
+			func() *v1.ClusterCustomLimitRange { return &v1.ClusterCustomLimitRange{} },
+			func() *v1.ClusterCustomLimitRangeList { return &v1.ClusterCustomLimitRangeList{} },
+			func(dst, src *v1.ClusterCustomLimitRangeList) { dst.ListMeta = src.ListMeta },
+			func(list *v1.ClusterCustomLimitRangeList) []*v1.ClusterCustomLimitRange {
+				return gentype.ToPointerSlice(list.Items)
+			},
+			func(list *v1.ClusterCustomLimitRangeList, items []*v1.ClusterCustomLimitRange) {
+				list.Items = gentype.FromPointerSlice(items)
+			},
+		),
+		fake,
+	}
+}
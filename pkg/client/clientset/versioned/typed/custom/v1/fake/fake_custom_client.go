@@ -0,0 +1,48 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1 "github.com/kubeservice-stack/custom-limit-range/pkg/client/clientset/versioned/typed/custom/v1"
+	rest "k8s.io/client-go/rest"
+	testing "k8s.io/client-go/testing"
+)
+
+type FakeCustomV1 struct {
+	*testing.Fake
+}
+
+func (c *FakeCustomV1) BandwidthQuotas(namespace string) v1.BandwidthQuotaInterface {
+	return newFakeBandwidthQuotas(c, namespace)
+}
+
+func (c *FakeCustomV1) ClusterCustomLimitRanges() v1.ClusterCustomLimitRangeInterface {
+	return newFakeClusterCustomLimitRanges(c)
+}
+
+func (c *FakeCustomV1) CustomLimitRanges(namespace string) v1.CustomLimitRangeInterface {
+	return newFakeCustomLimitRanges(c, namespace)
+}
+
+// RESTClient returns a RESTClient that is used to communicate
+// with API server by this client implementation.
+func (c *FakeCustomV1) RESTClient() rest.Interface {
+	var ret *rest.RESTClient
+	return ret
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	customv1 "github.com/kubeservice-stack/custom-limit-range/pkg/client/applyconfiguration/custom/v1"
+	typedcustomv1 "github.com/kubeservice-stack/custom-limit-range/pkg/client/clientset/versioned/typed/custom/v1"
+	v1 "github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+	gentype "k8s.io/client-go/gentype"
+)
+
+// fakeCustomLimitRanges implements CustomLimitRangeInterface
+type fakeCustomLimitRanges struct {
+	*gentype.FakeClientWithListAndApply[*v1.CustomLimitRange, *v1.CustomLimitRangeList, *customv1.CustomLimitRangeApplyConfiguration]
+	Fake *FakeCustomV1
+}
+
+func newFakeCustomLimitRanges(fake *FakeCustomV1, namespace string) typedcustomv1.CustomLimitRangeInterface {
+	return &fakeCustomLimitRanges{
+		gentype.NewFakeClientWithListAndApply[*v1.CustomLimitRange, *v1.CustomLimitRangeList, *customv1.CustomLimitRangeApplyConfiguration](
+			fake.Fake,
+			namespace,
+			v1.SchemeGroupVersion.WithResource("customlimitranges"),
+			v1.SchemeGroupVersion.WithKind("CustomLimitRange"),
+			func() *v1.CustomLimitRange { return &v1.CustomLimitRange{} },
+			func() *v1.CustomLimitRangeList { return &v1.CustomLimitRangeList{} },
+			func(dst, src *v1.CustomLimitRangeList) { dst.ListMeta = src.ListMeta },
+			func(list *v1.CustomLimitRangeList) []*v1.CustomLimitRange { return gentype.ToPointerSlice(list.Items) },
+			func(list *v1.CustomLimitRangeList, items []*v1.CustomLimitRange) {
+				list.Items = gentype.FromPointerSlice(items)
+			},
+		),
+		fake,
+	}
+}
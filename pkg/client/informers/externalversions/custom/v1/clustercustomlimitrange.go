@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	context "context"
+	time "time"
+
+	versioned "github.com/kubeservice-stack/custom-limit-range/pkg/client/clientset/versioned"
+	internalinterfaces "github.com/kubeservice-stack/custom-limit-range/pkg/client/informers/externalversions/internalinterfaces"
+	customv1 "github.com/kubeservice-stack/custom-limit-range/pkg/client/listers/custom/v1"
+	apiscustomv1 "github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// ClusterCustomLimitRangeInformer provides access to a shared informer and lister for
+// ClusterCustomLimitRanges.
+type ClusterCustomLimitRangeInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() customv1.ClusterCustomLimitRangeLister
+}
+
+type clusterCustomLimitRangeInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// NewClusterCustomLimitRangeInformer constructs a new informer for ClusterCustomLimitRange type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewClusterCustomLimitRangeInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredClusterCustomLimitRangeInformer(client, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredClusterCustomLimitRangeInformer constructs a new informer for ClusterCustomLimitRange type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredClusterCustomLimitRangeInformer(client versioned.Interface, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		cache.ToListWatcherWithWatchListSemantics(&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.CustomV1().ClusterCustomLimitRanges().List(context.Background(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.CustomV1().ClusterCustomLimitRanges().Watch(context.Background(), options)
+			},
+			ListWithContextFunc: func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.CustomV1().ClusterCustomLimitRanges().List(ctx, options)
+			},
+			WatchFuncWithContext: func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.CustomV1().ClusterCustomLimitRanges().Watch(ctx, options)
+			},
+		}, client),
+		&apiscustomv1.ClusterCustomLimitRange{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *clusterCustomLimitRangeInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredClusterCustomLimitRangeInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *clusterCustomLimitRangeInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&apiscustomv1.ClusterCustomLimitRange{}, f.defaultInformer)
+}
+
+func (f *clusterCustomLimitRangeInformer) Lister() customv1.ClusterCustomLimitRangeLister {
+	return customv1.NewClusterCustomLimitRangeLister(f.Informer().GetIndexer())
+}
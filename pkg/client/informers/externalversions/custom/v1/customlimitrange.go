@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	context "context"
+	time "time"
+
+	versioned "github.com/kubeservice-stack/custom-limit-range/pkg/client/clientset/versioned"
+	internalinterfaces "github.com/kubeservice-stack/custom-limit-range/pkg/client/informers/externalversions/internalinterfaces"
+	customv1 "github.com/kubeservice-stack/custom-limit-range/pkg/client/listers/custom/v1"
+	apiscustomv1 "github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// CustomLimitRangeInformer provides access to a shared informer and lister for
+// CustomLimitRanges.
+type CustomLimitRangeInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() customv1.CustomLimitRangeLister
+}
+
+type customLimitRangeInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+	namespace        string
+}
+
+// NewCustomLimitRangeInformer constructs a new informer for CustomLimitRange type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewCustomLimitRangeInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredCustomLimitRangeInformer(client, namespace, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredCustomLimitRangeInformer constructs a new informer for CustomLimitRange type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredCustomLimitRangeInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		cache.ToListWatcherWithWatchListSemantics(&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.CustomV1().CustomLimitRanges(namespace).List(context.Background(), options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.CustomV1().CustomLimitRanges(namespace).Watch(context.Background(), options)
+			},
+			ListWithContextFunc: func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.CustomV1().CustomLimitRanges(namespace).List(ctx, options)
+			},
+			WatchFuncWithContext: func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.CustomV1().CustomLimitRanges(namespace).Watch(ctx, options)
+			},
+		}, client),
+		&apiscustomv1.CustomLimitRange{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *customLimitRangeInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredCustomLimitRangeInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *customLimitRangeInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&apiscustomv1.CustomLimitRange{}, f.defaultInformer)
+}
+
+func (f *customLimitRangeInformer) Lister() customv1.CustomLimitRangeLister {
+	return customv1.NewCustomLimitRangeLister(f.Informer().GetIndexer())
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	internalinterfaces "github.com/kubeservice-stack/custom-limit-range/pkg/client/informers/externalversions/internalinterfaces"
+)
+
+// Interface provides access to all the informers in this group version.
+type Interface interface {
+	// BandwidthQuotas returns a BandwidthQuotaInformer.
+	BandwidthQuotas() BandwidthQuotaInformer
+	// ClusterCustomLimitRanges returns a ClusterCustomLimitRangeInformer.
+	ClusterCustomLimitRanges() ClusterCustomLimitRangeInformer
+	// CustomLimitRanges returns a CustomLimitRangeInformer.
+	CustomLimitRanges() CustomLimitRangeInformer
+}
+
+type version struct {
+	factory          internalinterfaces.SharedInformerFactory
+	namespace        string
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// New returns a new Interface.
+func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakListOptions internalinterfaces.TweakListOptionsFunc) Interface {
+	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
+}
+
+// BandwidthQuotas returns a BandwidthQuotaInformer.
+func (v *version) BandwidthQuotas() BandwidthQuotaInformer {
+	return &bandwidthQuotaInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// ClusterCustomLimitRanges returns a ClusterCustomLimitRangeInformer.
+func (v *version) ClusterCustomLimitRanges() ClusterCustomLimitRangeInformer {
+	return &clusterCustomLimitRangeInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// CustomLimitRanges returns a CustomLimitRangeInformer.
+func (v *version) CustomLimitRanges() CustomLimitRangeInformer {
+	return &customLimitRangeInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by informer-gen. DO NOT EDIT.
+
+package externalversions
+
+import (
+	fmt "fmt"
+
+	v1 "github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+	v2 "github.com/kubeservice-stack/custom-limit-range/pkg/webhook/v2"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// GenericInformer is type of SharedIndexInformer which will locate and delegate to other
+// sharedInformers based on type
+type GenericInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() cache.GenericLister
+}
+
+type genericInformer struct {
+	informer cache.SharedIndexInformer
+	resource schema.GroupResource
+}
+
+// Informer returns the SharedIndexInformer.
+func (f *genericInformer) Informer() cache.SharedIndexInformer {
+	return f.informer
+}
+
+// Lister returns the GenericLister.
+func (f *genericInformer) Lister() cache.GenericLister {
+	return cache.NewGenericLister(f.Informer().GetIndexer(), f.resource)
+}
+
+// ForResource gives generic access to a shared informer of the matching type
+// TODO extend this to unknown resources with a client pool
+func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
+	switch resource {
+	// Group=custom.cmss.com, Version=v1
+	case v1.SchemeGroupVersion.WithResource("bandwidthquotas"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Custom().V1().BandwidthQuotas().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("clustercustomlimitranges"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Custom().V1().ClusterCustomLimitRanges().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("customlimitranges"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Custom().V1().CustomLimitRanges().Informer()}, nil
+
+		// Group=custom.cmss.com, Version=v2
+	case v2.SchemeGroupVersion.WithResource("customlimitranges"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Custom().V2().CustomLimitRanges().Informer()}, nil
+
+	}
+
+	return nil, fmt.Errorf("no informer found for %v", resource)
+}
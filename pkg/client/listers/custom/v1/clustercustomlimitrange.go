@@ -0,0 +1,48 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	customv1 "github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+	labels "k8s.io/apimachinery/pkg/labels"
+	listers "k8s.io/client-go/listers"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// ClusterCustomLimitRangeLister helps list ClusterCustomLimitRanges.
+// All objects returned here must be treated as read-only.
+type ClusterCustomLimitRangeLister interface {
+	// List lists all ClusterCustomLimitRanges in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*customv1.ClusterCustomLimitRange, err error)
+	// Get retrieves the ClusterCustomLimitRange from the index for a given name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*customv1.ClusterCustomLimitRange, error)
+	ClusterCustomLimitRangeListerExpansion
+}
+
+// clusterCustomLimitRangeLister implements the ClusterCustomLimitRangeLister interface.
+type clusterCustomLimitRangeLister struct {
+	listers.ResourceIndexer[*customv1.ClusterCustomLimitRange]
+}
+
+// NewClusterCustomLimitRangeLister returns a new ClusterCustomLimitRangeLister.
+func NewClusterCustomLimitRangeLister(indexer cache.Indexer) ClusterCustomLimitRangeLister {
+	return &clusterCustomLimitRangeLister{listers.New[*customv1.ClusterCustomLimitRange](indexer, customv1.Resource("clustercustomlimitrange"))}
+}
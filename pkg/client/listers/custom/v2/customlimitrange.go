@@ -0,0 +1,70 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v2
+
+import (
+	customv2 "github.com/kubeservice-stack/custom-limit-range/pkg/webhook/v2"
+	labels "k8s.io/apimachinery/pkg/labels"
+	listers "k8s.io/client-go/listers"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// CustomLimitRangeLister helps list CustomLimitRanges.
+// All objects returned here must be treated as read-only.
+type CustomLimitRangeLister interface {
+	// List lists all CustomLimitRanges in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*customv2.CustomLimitRange, err error)
+	// CustomLimitRanges returns an object that can list and get CustomLimitRanges.
+	CustomLimitRanges(namespace string) CustomLimitRangeNamespaceLister
+	CustomLimitRangeListerExpansion
+}
+
+// customLimitRangeLister implements the CustomLimitRangeLister interface.
+type customLimitRangeLister struct {
+	listers.ResourceIndexer[*customv2.CustomLimitRange]
+}
+
+// NewCustomLimitRangeLister returns a new CustomLimitRangeLister.
+func NewCustomLimitRangeLister(indexer cache.Indexer) CustomLimitRangeLister {
+	return &customLimitRangeLister{listers.New[*customv2.CustomLimitRange](indexer, customv2.Resource("customlimitrange"))}
+}
+
+// CustomLimitRanges returns an object that can list and get CustomLimitRanges.
+func (s *customLimitRangeLister) CustomLimitRanges(namespace string) CustomLimitRangeNamespaceLister {
+	return customLimitRangeNamespaceLister{listers.NewNamespaced[*customv2.CustomLimitRange](s.ResourceIndexer, namespace)}
+}
+
+// CustomLimitRangeNamespaceLister helps list and get CustomLimitRanges.
+// All objects returned here must be treated as read-only.
+type CustomLimitRangeNamespaceLister interface {
+	// List lists all CustomLimitRanges in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*customv2.CustomLimitRange, err error)
+	// Get retrieves the CustomLimitRange from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*customv2.CustomLimitRange, error)
+	CustomLimitRangeNamespaceListerExpansion
+}
+
+// customLimitRangeNamespaceLister implements the CustomLimitRangeNamespaceLister
+// interface.
+type customLimitRangeNamespaceLister struct {
+	listers.ResourceIndexer[*customv2.CustomLimitRange]
+}
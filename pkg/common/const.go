@@ -29,6 +29,51 @@ const (
 	WebhookUrlPath = "/node/affinity"
 
 	WebhookPodDisable = "customlimitrange.kubernetes.io/limited"
+
+	IngressBandwidthAnnotation = "kubernetes.io/ingress-bandwidth"
+	EgressBandwidthAnnotation  = "kubernetes.io/egress-bandwidth"
+	// IngressBurstKey and EgressBurstKey name the bursts in the ranges of the policies. They are not
+	// pod annotations: the container runtimes hard-code the burst of the pod network, so bursts only
+	// bound the Multus networks, whose bandwidth is passed to the CNI bandwidth plugin.
+	IngressBurstKey = "ingress-burst"
+	EgressBurstKey  = "egress-burst"
+
+	RequestedIngressBandwidthAnnotation = "customlimitrange.kubernetes.io/requested-ingress-bandwidth"
+	RequestedEgressBandwidthAnnotation  = "customlimitrange.kubernetes.io/requested-egress-bandwidth"
+
+	// ProvenancePoliciesAnnotation lists the policies in effect when the webhook admitted a pod, by
+	// priority, as namespace/name@generation, or name@generation for a ClusterCustomLimitRange.
+	ProvenancePoliciesAnnotation = "customlimitrange.kubernetes.io/policies"
+	// ProvenanceDefaultedAnnotation lists the bandwidth annotations of a pod injected from the default of the policies.
+	ProvenanceDefaultedAnnotation = "customlimitrange.kubernetes.io/defaulted-annotations"
+	// ProvenanceValidatedAnnotation lists the bandwidth annotations of a pod set by the user and validated against the policies.
+	ProvenanceValidatedAnnotation = "customlimitrange.kubernetes.io/validated-annotations"
+
+	// BandwidthBackendAnnotation selects, on a namespace, the CNI backend of its pods, see injector.Backends.
+	BandwidthBackendAnnotation = "customlimitrange.kubernetes.io/bandwidth-backend"
+	// KubeOVNIngressRateAnnotation and KubeOVNEgressRateAnnotation are the rates, in Mbit/s, read by Kube-OVN.
+	KubeOVNIngressRateAnnotation = "ovn.kubernetes.io/ingress_rate"
+	KubeOVNEgressRateAnnotation  = "ovn.kubernetes.io/egress_rate"
+
+	// MultusNetworksAnnotation selects the Multus secondary networks of a pod, as a JSON list of
+	// network selection elements or as a comma separated list of namespace/name@interface.
+	MultusNetworksAnnotation = "k8s.v1.cni.cncf.io/networks"
+
+	// DefaultedAnnotation lists the fields of a CustomLimitRange filled by its defaultPolicy.
+	DefaultedAnnotation = "customlimitrange.kubernetes.io/defaulted"
+
+	// LimitRangeTypeAnnotation keeps the v1 limitrange type, which v2 does not have, when it is not Pod.
+	LimitRangeTypeAnnotation = "custom.cmss.com/v1-limitrange-type"
+
+	ReasonBandwidthOutOfRange     = "BandwidthOutOfRange"
+	ReasonBandwidthQuotaExceeded  = "BandwidthQuotaExceeded"
+	ReasonBandwidthDefaultMissing = "BandwidthDefaultMissing"
+	ReasonBandwidthRemediated     = "BandwidthRemediated"
+	ReasonBandwidthNotRemediated  = "BandwidthNotRemediated"
+
+	// RestartedAtAnnotation is set on the pod template of a workload to restart its pods, as
+	// kubectl rollout restart does.
+	RestartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
 )
 
 var (
@@ -37,9 +82,19 @@ var (
 	ErrFailedToCreatePatch       = errors.New("failed to create patch")
 	ErrMissingConfiguration      = errors.New("missing configuration")
 	ErrInvalidConfiguration      = errors.New("invalid configuration error")
+	ErrInvalidManifest           = errors.New("invalid manifest")
+	ErrInvalidArgument           = errors.New("invalid argument")
+	ErrUnknownBandwidthBackend   = errors.New("unknown bandwidth backend")
 
 	ErrInvalidBandwidthRange                   = errors.New("resource is unreasonably small (< 1kbit) or large (> 1Pbit)")
 	ErrInvalidBandwidthMaxMin                  = errors.New("resource must min <= default <= max")
+	ErrInvalidBurstRate                        = errors.New("burst must hold at least 1ms of traffic at the rate (burst >= rate/1000)")
+	ErrInvalidEnforcementMode                  = errors.New("enforcementMode must be one of enforce, clamp, warn, audit, dryRun")
+	ErrInvalidDefaultPolicy                    = errors.New("defaultPolicy must be one of none, min, max")
 	ErrInvalidPodSettingBandwidthMaxMin        = errors.New("pod annotation must:  min <= [kubernetes.io/ingress-bandwidth]/[kubernetes.io/egress-bandwidth] <= max")
+	ErrInvalidPodBandwidthAnnotation           = errors.New("pod bandwidth annotation must be a quantity")
+	ErrBandwidthQuotaExceeded                  = errors.New("pod bandwidth exceeds the BandwidthQuota of the namespace")
+	ErrBandwidthAnnotationChanged              = errors.New("pod bandwidth annotations cannot change once the pod is bound to a node")
+	ErrInvalidNetworkBandwidth                 = errors.New("network bandwidth must: min <= [k8s.v1.cni.cncf.io/networks] bandwidth <= max")
 	ErrInvalidCustomLimitRangeCountMoreThanOne = errors.New("Namespace has more than one CustomLimitRange Resource")
 )
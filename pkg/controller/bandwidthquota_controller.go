@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+)
+
+// BandwidthQuotaReconciler reconciles a BandwidthQuota object and keeps status.used
+// in line with the bandwidth claimed by the pods of its namespace.
+type BandwidthQuotaReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// Reconcile recomputes the bandwidth used in the namespace of the BandwidthQuota and writes it to its status.
+func (r *BandwidthQuotaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	bq := &webhook.BandwidthQuota{}
+	if err := r.Get(ctx, req.NamespacedName, bq); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(req.Namespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	used := webhook.BandwidthUsage(pods.Items, "")
+	status := webhook.BandwidthQuotaStatus{
+		Hard: webhook.BandwidthItems{Ingress: bq.Spec.Hard.Ingress, Egress: bq.Spec.Hard.Egress},
+		Used: webhook.BandwidthItems{Ingress: used.Ingress, Egress: used.Egress},
+	}
+	if equality.Semantic.DeepEqual(bq.Status, status) {
+		return ctrl.Result{}, nil
+	}
+
+	bq.Status = status
+	customlimitrangelog.Info("update bandwidthquota status", "namespace", bq.Namespace, "name", bq.Name, "status", bq.Status)
+	if err := r.Status().Update(ctx, bq); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BandwidthQuotaReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("bandwidthquota").
+		For(&webhook.BandwidthQuota{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// Usage changes when a pod is created or deleted, changes its bandwidth or terminates.
+		Watches(&corev1.Pod{},
+			handler.EnqueueRequestsFromMapFunc(r.namespaceBandwidthQuotas),
+			builder.WithPredicates(predicate.Or(predicate.AnnotationChangedPredicate{}, predicate.Funcs{
+				CreateFunc:  func(event.CreateEvent) bool { return false },
+				DeleteFunc:  func(event.DeleteEvent) bool { return false },
+				GenericFunc: func(event.GenericEvent) bool { return false },
+				UpdateFunc:  podPhaseChanged,
+			}))).
+		Complete(r)
+}
+
+func podPhaseChanged(e event.UpdateEvent) bool {
+	oldPod, ok := e.ObjectOld.(*corev1.Pod)
+	if !ok {
+		return false
+	}
+	newPod, ok := e.ObjectNew.(*corev1.Pod)
+	if !ok {
+		return false
+	}
+	return oldPod.Status.Phase != newPod.Status.Phase
+}
+
+// namespaceBandwidthQuotas maps an object to every BandwidthQuota in its namespace.
+func (r *BandwidthQuotaReconciler) namespaceBandwidthQuotas(ctx context.Context, obj client.Object) []reconcile.Request {
+	bql := &webhook.BandwidthQuotaList{}
+	if err := r.List(ctx, bql, client.InNamespace(obj.GetNamespace())); err != nil {
+		customlimitrangelog.Error(err, "list BandwidthQuota", "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(bql.Items))
+	for _, item := range bql.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: item.Namespace, Name: item.Name},
+		})
+	}
+	return requests
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+)
+
+func TestReconcileBandwidthQuota(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	done := newPod("done", map[string]string{common.IngressBandwidthAnnotation: "1G"})
+	done.Status.Phase = corev1.PodSucceeded
+	bq := &webhook.BandwidthQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "test-a"},
+		Spec: webhook.BandwidthQuotaSpec{
+			Hard: webhook.CustomItems{Ingress: resource.MustParse("1G"), Egress: resource.MustParse("1G")},
+		},
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(newScheme()).
+		WithStatusSubresource(&webhook.BandwidthQuota{}).
+		WithObjects(bq, done,
+			newPod("a", nil),
+			newPod("b", map[string]string{common.IngressBandwidthAnnotation: "300M", common.EgressBandwidthAnnotation: "100M"}),
+			newPod("c", map[string]string{common.IngressBandwidthAnnotation: "200M"}),
+		).Build()
+
+	r := &BandwidthQuotaReconciler{Client: c, Scheme: c.Scheme()}
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: "test-a", Name: "quota"}
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	assert.Nil(err)
+
+	got := &webhook.BandwidthQuota{}
+	assert.Nil(c.Get(ctx, key, got))
+	assert.Equal("1G", got.Status.Hard.Ingress.String())
+	assert.Equal("500M", got.Status.Used.Ingress.String())
+	assert.Equal("100M", got.Status.Used.Egress.String())
+
+	// usage is recomputed once a pod is deleted
+	assert.Nil(c.Delete(ctx, newPod("b", nil)))
+	_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	assert.Nil(err)
+	assert.Nil(c.Get(ctx, key, got))
+	assert.Equal("200M", got.Status.Used.Ingress.String())
+	assert.True(got.Status.Used.Egress.IsZero())
+
+	_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "test-a", Name: "missing"}})
+	assert.Nil(err)
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/policy"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+)
+
+// log is for logging in this package.
+var customlimitrangelog = logf.Log.WithName("customlimitrange-controller")
+
+const (
+	ReasonEvaluated        = "Evaluated"
+	ReasonMultiplePolicies = "MultiplePolicies"
+	ReasonSinglePolicy     = "SinglePolicy"
+)
+
+// CustomLimitRangeReconciler reconciles a CustomLimitRange object and reports
+// how the pods in its namespace relate to the configured bandwidth range.
+type CustomLimitRangeReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// Reconcile evaluates every pod in the namespace of the CustomLimitRange and writes the result to its status.
+func (r *CustomLimitRangeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	clr := &webhook.CustomLimitRange{}
+	if err := r.Get(ctx, req.NamespacedName, clr); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	clrl := &webhook.CustomLimitRangeList{}
+	if err := r.List(ctx, clrl, client.InNamespace(req.Namespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(req.Namespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	status := clr.Status.DeepCopy()
+	status.ObservedGeneration = clr.Generation
+	status.CompliantPods, status.DefaultedPods, status.OutOfRangePods = 0, 0, 0
+	policies := webhook.Policies(clrl.Items)
+	for i := range pods.Items {
+		// Pods are evaluated against the effective range, which merges every CustomLimitRange in the namespace.
+		effective := policy.Merge(policies, pods.Items[i].Labels).Range
+		switch classifyPod(&pods.Items[i], effective) {
+		case podCompliant:
+			status.CompliantPods++
+		case podDefaulted:
+			status.DefaultedPods++
+		case podOutOfRange:
+			status.OutOfRangePods++
+		}
+	}
+
+	if len(clrl.Items) > 1 {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               webhook.ConditionConflicting,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: clr.Generation,
+			Reason:             ReasonMultiplePolicies,
+			Message:            fmt.Sprintf("namespace has %d CustomLimitRange resources, limits are merged", len(clrl.Items)),
+		})
+	} else {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               webhook.ConditionConflicting,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: clr.Generation,
+			Reason:             ReasonSinglePolicy,
+			Message:            "no other CustomLimitRange in namespace",
+		})
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               webhook.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: clr.Generation,
+		Reason:             ReasonEvaluated,
+		Message:            fmt.Sprintf("evaluated %d pods", len(pods.Items)),
+	})
+	now := metav1.Now()
+	status.LastEvaluationTime = &now
+
+	clr.Status = *status
+	customlimitrangelog.Info("update status", "namespace", clr.Namespace, "name", clr.Name, "status", clr.Status)
+	if err := r.Status().Update(ctx, clr); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CustomLimitRangeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("customlimitrange").
+		For(&webhook.CustomLimitRange{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// Creating or deleting a CustomLimitRange changes the Conflicting condition of its siblings.
+		Watches(&webhook.CustomLimitRange{},
+			handler.EnqueueRequestsFromMapFunc(r.namespaceCustomLimitRanges),
+			builder.WithPredicates(predicate.Funcs{
+				UpdateFunc: func(event.UpdateEvent) bool { return false },
+			})).
+		Watches(&corev1.Pod{},
+			handler.EnqueueRequestsFromMapFunc(r.namespaceCustomLimitRanges),
+			builder.WithPredicates(predicate.AnnotationChangedPredicate{})).
+		Complete(r)
+}
+
+// namespaceCustomLimitRanges maps an object to every CustomLimitRange in its namespace.
+func (r *CustomLimitRangeReconciler) namespaceCustomLimitRanges(ctx context.Context, obj client.Object) []reconcile.Request {
+	clrl := &webhook.CustomLimitRangeList{}
+	if err := r.List(ctx, clrl, client.InNamespace(obj.GetNamespace())); err != nil {
+		customlimitrangelog.Error(err, "list CustomLimitRange", "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(clrl.Items))
+	for _, item := range clrl.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: item.Namespace, Name: item.Name},
+		})
+	}
+	return requests
+}
+
+type podClass int
+
+const (
+	podIgnored podClass = iota
+	podCompliant
+	podDefaulted
+	podOutOfRange
+)
+
+// classifyPod reports whether the pod bandwidth annotations are within the range or defaulted.
+func classifyPod(pod *corev1.Pod, r policy.Range) podClass {
+	if policy.Disabled(pod.Annotations) {
+		return podIgnored
+	}
+
+	if r.OutOfRange(pod.Annotations) {
+		return podOutOfRange
+	}
+	if _, ok := pod.Annotations[common.ProvenancePoliciesAnnotation]; ok {
+		if pod.Annotations[common.ProvenanceDefaultedAnnotation] != "" {
+			return podDefaulted
+		}
+		return podCompliant
+	}
+	if r.Defaulted(pod.Annotations) {
+		return podDefaulted
+	}
+	return podCompliant
+}
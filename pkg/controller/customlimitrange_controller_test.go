@@ -0,0 +1,170 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+)
+
+func newScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = webhook.AddToScheme(scheme)
+	return scheme
+}
+
+func newPod(name string, an map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-a", Annotations: an},
+	}
+}
+
+func newCustomLimitRange(name string) *webhook.CustomLimitRange {
+	return &webhook.CustomLimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-a", Generation: 2},
+		Spec: webhook.CustomLimitRangeSpec{
+			LRange: webhook.LimitRange{
+				Type: "Pod",
+				BandwidthRange: webhook.BandwidthRange{
+					Max:     webhook.CustomItems{Ingress: resource.MustParse("1G"), Egress: resource.MustParse("1G")},
+					Min:     webhook.CustomItems{Ingress: resource.MustParse("100M"), Egress: resource.MustParse("100M")},
+					Default: webhook.CustomItems{Ingress: resource.MustParse("500M"), Egress: resource.MustParse("500M")},
+				},
+			},
+		},
+	}
+}
+
+func TestClassifyPod(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	lr := newCustomLimitRange("test").Spec.LRange.PodBounds()
+	testCases := []struct {
+		name     string
+		an       map[string]string
+		expected podClass
+	}{
+		{
+			name:     "NoAnnotation",
+			an:       nil,
+			expected: podCompliant,
+		},
+		{
+			name:     "InRange",
+			an:       map[string]string{common.IngressBandwidthAnnotation: "200M"},
+			expected: podCompliant,
+		},
+		{
+			name:     "Defaulted",
+			an:       map[string]string{common.IngressBandwidthAnnotation: "500M", common.EgressBandwidthAnnotation: "500000k"},
+			expected: podDefaulted,
+		},
+		{
+			name:     "OverMax",
+			an:       map[string]string{common.EgressBandwidthAnnotation: "10G"},
+			expected: podOutOfRange,
+		},
+		{
+			name:     "UnderMin",
+			an:       map[string]string{common.IngressBandwidthAnnotation: "500M", common.EgressBandwidthAnnotation: "1M"},
+			expected: podOutOfRange,
+		},
+		{
+			name:     "Unparsable",
+			an:       map[string]string{common.IngressBandwidthAnnotation: "10Gbps"},
+			expected: podOutOfRange,
+		},
+		{
+			name:     "Disabled",
+			an:       map[string]string{common.WebhookPodDisable: "disable", common.EgressBandwidthAnnotation: "10G"},
+			expected: podIgnored,
+		},
+		{
+			name: "ProvenanceUserSetDefault",
+			an: map[string]string{common.IngressBandwidthAnnotation: "500M", common.EgressBandwidthAnnotation: "500M",
+				common.ProvenancePoliciesAnnotation: "test/test@1"},
+			expected: podCompliant,
+		},
+		{
+			name: "ProvenanceDefaulted",
+			an: map[string]string{common.IngressBandwidthAnnotation: "200M", common.EgressBandwidthAnnotation: "500M",
+				common.ProvenancePoliciesAnnotation: "test/test@1", common.ProvenanceDefaultedAnnotation: common.EgressBandwidthAnnotation},
+			expected: podDefaulted,
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(tc.expected, classifyPod(newPod(tc.name, tc.an), lr), tc.name)
+	}
+}
+
+func TestReconcileStatus(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	clr := newCustomLimitRange("test")
+	c := fake.NewClientBuilder().
+		WithScheme(newScheme()).
+		WithStatusSubresource(&webhook.CustomLimitRange{}).
+		WithObjects(clr,
+			newPod("a", nil),
+			newPod("b", map[string]string{common.IngressBandwidthAnnotation: "500M"}),
+			newPod("c", map[string]string{common.IngressBandwidthAnnotation: "10G"}),
+		).Build()
+
+	r := &CustomLimitRangeReconciler{Client: c, Scheme: c.Scheme()}
+	ctx := context.Background()
+	key := types.NamespacedName{Namespace: "test-a", Name: "test"}
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	assert.Nil(err)
+
+	got := &webhook.CustomLimitRange{}
+	assert.Nil(c.Get(ctx, key, got))
+	assert.Equal(int64(2), got.Status.ObservedGeneration)
+	assert.Equal(int32(1), got.Status.CompliantPods)
+	assert.Equal(int32(1), got.Status.DefaultedPods)
+	assert.Equal(int32(1), got.Status.OutOfRangePods)
+	assert.NotNil(got.Status.LastEvaluationTime)
+	assert.True(meta.IsStatusConditionTrue(got.Status.Conditions, webhook.ConditionReady))
+	assert.True(meta.IsStatusConditionFalse(got.Status.Conditions, webhook.ConditionConflicting))
+
+	assert.Nil(c.Create(ctx, newCustomLimitRange("other")))
+	_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+	assert.Nil(err)
+	assert.Nil(c.Get(ctx, key, got))
+	assert.True(meta.IsStatusConditionTrue(got.Status.Conditions, webhook.ConditionReady))
+	assert.True(meta.IsStatusConditionTrue(got.Status.Conditions, webhook.ConditionConflicting))
+
+	_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "test-a", Name: "missing"}})
+	assert.Nil(err)
+}
@@ -0,0 +1,396 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/injector"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/policy"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+)
+
+// remediationRetry is the delay before a pod whose workload cannot be restarted yet is checked again.
+const remediationRetry = time.Minute
+
+// PodComplianceReconciler records events on the running pods out of compliance with the policies and restarts their workloads.
+type PodComplianceReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// Remediate restarts the workload owning a pod out of compliance, when its template complies.
+	Remediate bool
+	// Limiter bounds the rate of the restarts across workloads, nil does not bound it.
+	Limiter flowcontrol.RateLimiter
+	// Cooldown is the minimum time between two restarts of a workload.
+	Cooldown time.Duration
+
+	// restarts records the last restart of the workloads by UID, which the cache may not show yet.
+	mu       sync.Mutex
+	restarts map[types.UID]time.Time
+	// reported records a digest of the findings last recorded as events on the pods.
+	reported map[types.NamespacedName]uint64
+}
+
+// Reconcile evaluates the pod as the webhook would admit it now and records the findings as events.
+func (r *PodComplianceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	pod := &corev1.Pod{}
+	if err := r.Get(ctx, req.NamespacedName, pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.report(req.NamespacedName, "", nil)
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed ||
+		policy.Disabled(pod.Annotations) {
+		r.report(req.NamespacedName, "", nil)
+		return ctrl.Result{}, nil
+	}
+
+	policies, _, err := injector.PoliciesFor(ctx, r.Client, pod.Namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	findings := checkPod(policies, pod)
+	changed := r.report(req.NamespacedName, pod.UID, findings)
+	if len(findings) == 0 {
+		return ctrl.Result{}, nil
+	}
+	if changed {
+		customlimitrangelog.Info("pod out of compliance", "namespace", pod.Namespace, "name", pod.Name, "findings", len(findings))
+		for _, f := range findings {
+			r.Recorder.Event(pod, corev1.EventTypeWarning, f.reason, f.message)
+		}
+	}
+	if !r.Remediate {
+		return ctrl.Result{}, nil
+	}
+	return r.remediate(ctx, pod, policies, changed)
+}
+
+// report records the findings of the pod and reports whether they changed.
+func (r *PodComplianceReconciler) report(key types.NamespacedName, uid types.UID, findings []finding) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(findings) == 0 {
+		delete(r.reported, key)
+		return false
+	}
+	h := fnv.New64a()
+	h.Write([]byte(uid))
+	for _, f := range findings {
+		fmt.Fprintf(h, "\x00%s\x00%s", f.reason, f.message)
+	}
+	digest := h.Sum64()
+	if last, ok := r.reported[key]; ok && last == digest {
+		return false
+	}
+	if r.reported == nil {
+		r.reported = map[types.NamespacedName]uint64{}
+	}
+	r.reported[key] = digest
+	return true
+}
+
+// finding is a reason why a pod does not comply with the policies, recorded as an event on the pod.
+type finding struct {
+	reason, message string
+}
+
+// checkPod returns the invalid, out of range and missing bandwidth of the pod and its networks.
+func checkPod(policies []policy.Policy, pod *corev1.Pod) []finding {
+	res := policy.EvaluatePod(policies, pod)
+	var findings []finding
+	if errors.Is(res.Cause, common.ErrInvalidPodBandwidthAnnotation) {
+		for _, reason := range res.Reasons {
+			findings = append(findings, finding{common.ReasonBandwidthOutOfRange, reason})
+		}
+		return findings
+	}
+	for _, v := range res.Violations {
+		findings = append(findings, finding{common.ReasonBandwidthOutOfRange, v.String()})
+	}
+	if res.Mode != policy.ModeDryRun {
+		for _, key := range res.Defaulted {
+			findings = append(findings, finding{common.ReasonBandwidthDefaultMissing,
+				fmt.Sprintf("%s is not set, %s sets the default %s", key, res.Source, res.Annotations[key])})
+		}
+	}
+
+	networks := policy.EvaluateNetworks(policies, pod.Namespace, pod.Annotations)
+	if networks.Decision == policy.Deny {
+		for _, reason := range networks.Reasons {
+			findings = append(findings, finding{common.ReasonBandwidthOutOfRange, reason})
+		}
+		return findings
+	}
+	for _, v := range networks.Violations {
+		findings = append(findings, finding{common.ReasonBandwidthOutOfRange, v.String()})
+	}
+	if networks.Mode != policy.ModeDryRun {
+		for _, key := range networks.Defaulted {
+			findings = append(findings, finding{common.ReasonBandwidthDefaultMissing,
+				fmt.Sprintf("%s is not set, %s sets a default", key, networks.Source)})
+		}
+	}
+	return findings
+}
+
+// remediate restarts the Deployment or StatefulSet owning the pod when its template complies, as kubectl rollout restart does.
+func (r *PodComplianceReconciler) remediate(ctx context.Context, pod *corev1.Pod, policies []policy.Policy, changed bool) (ctrl.Result, error) {
+	workload, err := r.workloadOf(ctx, pod)
+	if err != nil || workload == nil {
+		return ctrl.Result{}, err
+	}
+	kind, template := workloadTemplate(workload)
+
+	if reason := templateNonCompliance(policies, workload.GetNamespace(), template); reason != "" {
+		if !changed {
+			return ctrl.Result{}, nil
+		}
+		r.Recorder.Eventf(pod, corev1.EventTypeWarning, common.ReasonBandwidthNotRemediated,
+			"%s %s not restarted: %s", kind, workload.GetName(), reason)
+		return ctrl.Result{}, nil
+	}
+	if rollingOut(workload) {
+		return ctrl.Result{RequeueAfter: remediationRetry}, nil
+	}
+	if at, err := time.Parse(time.RFC3339, template.Annotations[common.RestartedAtAnnotation]); err == nil {
+		if wait := r.Cooldown - time.Since(at); wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+	}
+	if wait := r.restartWait(workload.GetUID()); wait > 0 {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+	if r.Limiter != nil && !r.Limiter.TryAccept() {
+		return ctrl.Result{RequeueAfter: remediationRetry}, nil
+	}
+
+	patch := client.MergeFrom(workload.DeepCopyObject().(client.Object))
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[common.RestartedAtAnnotation] = time.Now().Format(time.RFC3339)
+	if err := r.Patch(ctx, workload, patch); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	r.restarted(workload.GetUID())
+	customlimitrangelog.Info("restart workload", "kind", kind, "namespace", workload.GetNamespace(), "name", workload.GetName(), "pod", pod.Name)
+	r.Recorder.Eventf(workload, corev1.EventTypeNormal, common.ReasonBandwidthRemediated,
+		"restarted, pod %s is out of compliance with the bandwidth policies", pod.Name)
+	return ctrl.Result{}, nil
+}
+
+// restartWait returns how long the workload must wait before it is restarted again.
+func (r *PodComplianceReconciler) restartWait(uid types.UID) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	at, ok := r.restarts[uid]
+	if !ok {
+		return 0
+	}
+	return max(r.Cooldown, remediationRetry) - time.Since(at)
+}
+
+// restarted records a restart of the workload, and forgets the restarts no longer delaying any.
+func (r *PodComplianceReconciler) restarted(uid types.UID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.restarts == nil {
+		r.restarts = map[types.UID]time.Time{}
+	}
+	for id, at := range r.restarts {
+		if time.Since(at) > max(r.Cooldown, remediationRetry) {
+			delete(r.restarts, id)
+		}
+	}
+	r.restarts[uid] = time.Now()
+}
+
+// templateNonCompliance returns why the pods of the template would not comply, or "".
+func templateNonCompliance(policies []policy.Policy, namespace string, template *corev1.PodTemplateSpec) string {
+	if policy.Disabled(template.Annotations) {
+		return "its pods opt out of the bandwidth policies"
+	}
+	res := policy.EvaluateTemplate(policies, template)
+	if res.Decision == policy.Deny {
+		return "the webhook would deny its pods: " + strings.Join(res.Reasons, "; ")
+	}
+	if res.Mode == policy.ModeDryRun {
+		return "the policies are in dryRun mode, which leaves pods unchanged"
+	}
+	if admitted := policy.Evaluate(policies, template.Labels, res.Annotations); len(admitted.Violations) > 0 {
+		return fmt.Sprintf("its pod template is out of range in %s mode: %s", res.Mode, admitted.Violations[0].String())
+	}
+	networks := policy.EvaluateNetworks(policies, namespace, res.Annotations)
+	if networks.Decision == policy.Deny {
+		return "the webhook would deny its pods: " + strings.Join(networks.Reasons, "; ")
+	}
+	if admitted := policy.EvaluateNetworks(policies, namespace, networks.Annotations); len(admitted.Violations) > 0 {
+		return fmt.Sprintf("its pod template is out of range in %s mode: %s", networks.Mode, admitted.Violations[0].String())
+	}
+	return ""
+}
+
+// workloadOf returns the Deployment or StatefulSet controlling the pod, nil for the other pods.
+func (r *PodComplianceReconciler) workloadOf(ctx context.Context, pod *corev1.Pod) (client.Object, error) {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil || !isApps(ref) {
+		return nil, nil
+	}
+	switch ref.Kind {
+	case "StatefulSet":
+		return r.owner(ctx, pod.Namespace, ref, &appsv1.StatefulSet{})
+	case "ReplicaSet":
+		rs, err := r.owner(ctx, pod.Namespace, ref, &appsv1.ReplicaSet{})
+		if err != nil || rs == nil {
+			return nil, err
+		}
+		ref = metav1.GetControllerOf(rs)
+		if ref == nil || !isApps(ref) || ref.Kind != "Deployment" {
+			return nil, nil
+		}
+		return r.owner(ctx, pod.Namespace, ref, &appsv1.Deployment{})
+	}
+	return nil, nil
+}
+
+// owner gets the object of the owner reference, nil when it no longer exists.
+func (r *PodComplianceReconciler) owner(ctx context.Context, namespace string, ref *metav1.OwnerReference, obj client.Object) (client.Object, error) {
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, obj); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	if obj.GetUID() != ref.UID {
+		return nil, nil
+	}
+	return obj, nil
+}
+
+func isApps(ref *metav1.OwnerReference) bool {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	return err == nil && gv.Group == appsv1.GroupName
+}
+
+// workloadTemplate returns the kind and the pod template of a workload.
+func workloadTemplate(obj client.Object) (string, *corev1.PodTemplateSpec) {
+	switch w := obj.(type) {
+	case *appsv1.Deployment:
+		return "Deployment", &w.Spec.Template
+	case *appsv1.StatefulSet:
+		return "StatefulSet", &w.Spec.Template
+	}
+	return "", nil
+}
+
+// rollingOut reports whether the workload still runs pods of a previous template.
+func rollingOut(obj client.Object) bool {
+	switch w := obj.(type) {
+	case *appsv1.Deployment:
+		replicas := int32(1)
+		if w.Spec.Replicas != nil {
+			replicas = *w.Spec.Replicas
+		}
+		return w.Status.ObservedGeneration < w.Generation || w.Status.UpdatedReplicas < replicas ||
+			w.Status.Replicas > w.Status.UpdatedReplicas
+	case *appsv1.StatefulSet:
+		return w.Status.ObservedGeneration < w.Generation || w.Status.UpdateRevision != w.Status.CurrentRevision
+	}
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PodComplianceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("podcompliance").
+		For(&corev1.Pod{}, builder.WithPredicates(predicate.Or(predicate.AnnotationChangedPredicate{}, predicate.LabelChangedPredicate{}))).
+		// Creating, changing or deleting a policy changes the compliance of the pods it applies to.
+		Watches(&webhook.CustomLimitRange{},
+			handler.EnqueueRequestsFromMapFunc(r.namespacePods),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&webhook.ClusterCustomLimitRange{},
+			handler.EnqueueRequestsFromMapFunc(r.clusterPods),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
+
+// namespacePods maps an object to every pod in its namespace.
+func (r *PodComplianceReconciler) namespacePods(ctx context.Context, obj client.Object) []reconcile.Request {
+	return r.pods(ctx, client.InNamespace(obj.GetNamespace()))
+}
+
+// clusterPods maps a ClusterCustomLimitRange to the pods of the namespaces it selects.
+func (r *PodComplianceReconciler) clusterPods(ctx context.Context, obj client.Object) []reconcile.Request {
+	cclr, ok := obj.(*webhook.ClusterCustomLimitRange)
+	if !ok || cclr.Spec.NamespaceSelector == nil {
+		return r.pods(ctx)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(cclr.Spec.NamespaceSelector)
+	if err != nil {
+		customlimitrangelog.Error(err, "parse namespaceSelector", "name", cclr.Name)
+		return nil
+	}
+	namespaces := &corev1.NamespaceList{}
+	if err := r.List(ctx, namespaces, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		customlimitrangelog.Error(err, "list Namespace")
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, ns := range namespaces.Items {
+		requests = append(requests, r.pods(ctx, client.InNamespace(ns.Name))...)
+	}
+	return requests
+}
+
+func (r *PodComplianceReconciler) pods(ctx context.Context, opts ...client.ListOption) []reconcile.Request {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, opts...); err != nil {
+		customlimitrangelog.Error(err, "list Pod")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(pods.Items))
+	for _, item := range pods.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: item.Namespace, Name: item.Name},
+		})
+	}
+	return requests
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+)
+
+// newDeployment returns a rolled out Deployment, with the ReplicaSet and a pod of its template.
+func newDeployment(name string, an map[string]string) (*appsv1.Deployment, *appsv1.ReplicaSet, *corev1.Pod) {
+	replicas := int32(1)
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-a", UID: types.UID(name), Generation: 1},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Annotations: an}},
+		},
+		Status: appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1},
+	}
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: name + "-1", Namespace: "test-a", UID: types.UID(name + "-1")}}
+	rs.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(deploy, appsv1.SchemeGroupVersion.WithKind("Deployment"))}
+	pod := newPod(name+"-1-a", an)
+	pod.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(rs, appsv1.SchemeGroupVersion.WithKind("ReplicaSet"))}
+	return deploy, rs, pod
+}
+
+func events(recorder *record.FakeRecorder) []string {
+	var got []string
+	for {
+		select {
+		case e := <-recorder.Events:
+			got = append(got, e)
+		default:
+			return got
+		}
+	}
+}
+
+func TestCheckPod(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	clr := newCustomLimitRange("test")
+	policies := webhook.Policies([]webhook.CustomLimitRange{*clr})
+
+	assert.Empty(checkPod(policies, newPod("a", map[string]string{
+		common.IngressBandwidthAnnotation: "500M", common.EgressBandwidthAnnotation: "500M",
+	})))
+	assert.Equal([]finding{
+		{common.ReasonBandwidthOutOfRange, "kubernetes.io/ingress-bandwidth=10G: must be at most 1G, the max set by CustomLimitRange test-a/test"},
+	}, checkPod(policies, newPod("b", map[string]string{
+		common.IngressBandwidthAnnotation: "10G", common.EgressBandwidthAnnotation: "500M",
+	})))
+	assert.Equal([]finding{
+		{common.ReasonBandwidthDefaultMissing, "kubernetes.io/egress-bandwidth is not set, CustomLimitRange test-a/test sets the default 500M"},
+	}, checkPod(policies, newPod("c", map[string]string{common.IngressBandwidthAnnotation: "500M"})))
+
+	invalid := checkPod(policies, newPod("d", map[string]string{common.IngressBandwidthAnnotation: "fast"}))
+	assert.Len(invalid, 1)
+	assert.Equal(common.ReasonBandwidthOutOfRange, invalid[0].reason)
+
+	// the networks the pod attaches to are checked against the ranges of the networks
+	clr.Spec.Networks = []webhook.NetworkLimitRange{{Name: "storage", BandwidthRange: webhook.BandwidthRange{
+		Max: webhook.CustomItems{Ingress: resource.MustParse("10G")},
+	}}}
+	policies = webhook.Policies([]webhook.CustomLimitRange{*clr})
+	networks := checkPod(policies, newPod("g", map[string]string{
+		common.IngressBandwidthAnnotation: "500M", common.EgressBandwidthAnnotation: "500M",
+		common.MultusNetworksAnnotation: `[{"name":"storage","bandwidth":{"ingressRate":20000000000,"ingressBurst":1000000000}}]`,
+	}))
+	assert.Len(networks, 1)
+	assert.Equal(common.ReasonBandwidthOutOfRange, networks[0].reason)
+	assert.Contains(networks[0].message, "metadata.annotations[k8s.v1.cni.cncf.io/networks][0].bandwidth.ingressRate")
+	clr.Spec.Networks = nil
+
+	// dryRun leaves pods unchanged, only the values out of range are reported
+	clr.Spec.EnforcementMode = webhook.EnforcementModeDryRun
+	policies = webhook.Policies([]webhook.CustomLimitRange{*clr})
+	assert.Empty(checkPod(policies, newPod("e", nil)))
+	assert.Len(checkPod(policies, newPod("f", map[string]string{common.IngressBandwidthAnnotation: "10G"})), 1)
+}
+
+func TestReconcilePodCompliance(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	deploy, rs, pod := newDeployment("web", map[string]string{common.IngressBandwidthAnnotation: "500M"})
+	other := pod.DeepCopy()
+	other.Name = "web-1-b"
+	c := fake.NewClientBuilder().
+		WithScheme(newScheme()).
+		WithObjects(newCustomLimitRange("test"), deploy, rs, pod, other).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &PodComplianceReconciler{Client: c, Scheme: c.Scheme(), Recorder: recorder, Cooldown: time.Hour}
+	ctx := context.Background()
+
+	// without Remediate, the findings are only recorded
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)})
+	assert.Nil(err)
+	assert.Equal([]string{
+		"Warning BandwidthDefaultMissing kubernetes.io/egress-bandwidth is not set, CustomLimitRange test-a/test sets the default 500M",
+	}, events(recorder))
+
+	// the Deployment is restarted once, the other pods wait for the cooldown
+	r.Remediate = true
+	res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)})
+	assert.Nil(err)
+	assert.Zero(res.RequeueAfter)
+	got := &appsv1.Deployment{}
+	assert.Nil(c.Get(ctx, client.ObjectKeyFromObject(deploy), got))
+	assert.Contains(got.Spec.Template.Annotations, common.RestartedAtAnnotation)
+	// the findings, unchanged, are not recorded again
+	assert.Equal([]string{"Normal BandwidthRemediated restarted, pod web-1-a is out of compliance with the bandwidth policies"}, events(recorder))
+
+	res, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(other)})
+	assert.Nil(err)
+	assert.Greater(res.RequeueAfter, 59*time.Minute)
+	assert.Len(events(recorder), 1)
+
+	// compliant, disabled and deleted pods are left alone
+	assert.Nil(c.Create(ctx, newPod("ok", map[string]string{
+		common.IngressBandwidthAnnotation: "500M", common.EgressBandwidthAnnotation: "500M",
+	})))
+	assert.Nil(c.Create(ctx, newPod("disabled", map[string]string{common.WebhookPodDisable: "disable"})))
+	for _, name := range []string{"ok", "disabled", "missing"} {
+		res, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "test-a", Name: name}})
+		assert.Nil(err)
+		assert.Zero(res.RequeueAfter)
+	}
+	assert.Empty(events(recorder))
+}
+
+func TestRemediateOnceWithStaleCache(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	deploy, rs, pod := newDeployment("web", map[string]string{common.IngressBandwidthAnnotation: "500M"})
+	other := pod.DeepCopy()
+	other.Name = "web-1-b"
+	// the patches are not applied, as the cache does not show them yet
+	patches := 0
+	c := fake.NewClientBuilder().
+		WithScheme(newScheme()).
+		WithObjects(newCustomLimitRange("test"), deploy, rs, pod, other).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(context.Context, client.WithWatch, client.Object, client.Patch, ...client.PatchOption) error {
+				patches++
+				return nil
+			},
+		}).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &PodComplianceReconciler{Client: c, Scheme: c.Scheme(), Recorder: recorder, Remediate: true}
+	ctx := context.Background()
+
+	res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)})
+	assert.Nil(err)
+	assert.Zero(res.RequeueAfter)
+	res, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(other)})
+	assert.Nil(err)
+	assert.Greater(res.RequeueAfter, time.Duration(0))
+	assert.Equal(1, patches)
+}
+
+func TestRemediatePodCompliance(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	clr := newCustomLimitRange("test")
+	// the webhook would deny the pods of the template
+	denied, deniedRS, deniedPod := newDeployment("denied", map[string]string{common.IngressBandwidthAnnotation: "10G"})
+	// a rollout is in progress
+	rolling, rollingRS, rollingPod := newDeployment("rolling", nil)
+	rolling.Status.UpdatedReplicas = 0
+	// the restarts are rate limited
+	limited, limitedRS, limitedPod := newDeployment("limited", nil)
+	// a pod without workload
+	standalone := newPod("standalone", nil)
+	c := fake.NewClientBuilder().
+		WithScheme(newScheme()).
+		WithObjects(clr, denied, deniedRS, deniedPod, rolling, rollingRS, rollingPod, limited, limitedRS, limitedPod, standalone).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &PodComplianceReconciler{Client: c, Scheme: c.Scheme(), Recorder: recorder, Remediate: true,
+		Limiter: flowcontrol.NewFakeNeverRateLimiter(), Cooldown: time.Hour}
+	ctx := context.Background()
+
+	res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(deniedPod)})
+	assert.Nil(err)
+	assert.Zero(res.RequeueAfter)
+	got := events(recorder)
+	assert.Len(got, 2)
+	assert.Contains(got[1], "Warning BandwidthNotRemediated Deployment denied not restarted: the webhook would deny its pods: ")
+
+	// checked again, a pod with the same findings is not reported again
+	res, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(deniedPod)})
+	assert.Nil(err)
+	assert.Zero(res.RequeueAfter)
+	assert.Empty(events(recorder))
+
+	for _, pod := range []*corev1.Pod{rollingPod, limitedPod} {
+		res, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pod)})
+		assert.Nil(err)
+		assert.Equal(remediationRetry, res.RequeueAfter)
+	}
+	res, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(standalone)})
+	assert.Nil(err)
+	assert.Zero(res.RequeueAfter)
+
+	for _, deploy := range []*appsv1.Deployment{denied, rolling, limited} {
+		got := &appsv1.Deployment{}
+		assert.Nil(c.Get(ctx, client.ObjectKeyFromObject(deploy), got))
+		assert.NotContains(got.Spec.Template.Annotations, common.RestartedAtAnnotation)
+	}
+
+	// in warn mode, the pods of a template out of range would still be out of range
+	clr.Spec.EnforcementMode = webhook.EnforcementModeWarn
+	policies := webhook.Policies([]webhook.CustomLimitRange{*clr})
+	assert.Contains(templateNonCompliance(policies, "test-a", &denied.Spec.Template), "its pod template is out of range in warn mode")
+	clr.Spec.EnforcementMode = webhook.EnforcementModeClamp
+	policies = webhook.Policies([]webhook.CustomLimitRange{*clr})
+	assert.Empty(templateNonCompliance(policies, "test-a", &denied.Spec.Template))
+}
+
+func TestClusterPods(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	other := newPod("other", nil)
+	other.Namespace = "test-b"
+	c := fake.NewClientBuilder().
+		WithScheme(newScheme()).
+		WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-a", Labels: map[string]string{"tenant": "true"}}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-b"}},
+			newPod("web", nil), other).
+		Build()
+	r := &PodComplianceReconciler{Client: c, Scheme: c.Scheme()}
+	ctx := context.Background()
+
+	// only the pods of the selected namespaces are checked again
+	cclr := &webhook.ClusterCustomLimitRange{ObjectMeta: metav1.ObjectMeta{Name: "tenant"}}
+	cclr.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "true"}}
+	assert.Equal([]ctrl.Request{{NamespacedName: types.NamespacedName{Namespace: "test-a", Name: "web"}}}, r.clusterPods(ctx, cclr))
+
+	cclr.Spec.NamespaceSelector = nil
+	assert.Len(r.clusterPods(ctx, cclr), 2)
+}
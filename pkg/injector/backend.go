@@ -0,0 +1,162 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/policy"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+)
+
+// Backend adds the annotations of a CNI plugin derived from the bandwidth annotations of a pod.
+type Backend interface {
+	// Annotate sets the annotations of the plugin and warns about the bandwidth it does not enforce.
+	Annotate(an map[string]string, bandwidth webhook.CustomItems) []string
+}
+
+const (
+	// BackendBandwidth is the CNI bandwidth plugin, which reads the annotations the policies bound.
+	BackendBandwidth = "bandwidth"
+	// BackendCilium is the bandwidth manager of Cilium, which only limits egress.
+	BackendCilium = "cilium"
+	// BackendKubeOVN is Kube-OVN, which reads its own annotations in Mbit/s and has no burst.
+	BackendKubeOVN = "kube-ovn"
+	// BackendMultus passes the bandwidth annotations to the delegate of the cluster network.
+	BackendMultus = "multus"
+)
+
+// Backends are the CNI backends by name, for the --bandwidth-backend flag and the BandwidthBackendAnnotation.
+var Backends = map[string]Backend{
+	BackendBandwidth: bandwidthBackend{},
+	BackendCilium:    ciliumBackend{},
+	BackendKubeOVN:   kubeOVNBackend{},
+	BackendMultus:    bandwidthBackend{},
+}
+
+// BackendNames returns the names of the backends, sorted.
+func BackendNames() []string {
+	names := make([]string, 0, len(Backends))
+	for name := range Backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupBackend returns the backend of the given name, the CNI bandwidth plugin when it is empty.
+func LookupBackend(name string) (Backend, error) {
+	if name == "" {
+		name = BackendBandwidth
+	}
+	b, ok := Backends[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q, expected one of %v", common.ErrUnknownBandwidthBackend, name, BackendNames())
+	}
+	return b, nil
+}
+
+// bandwidthBackend reads the annotations the policies bound, it has nothing to add.
+type bandwidthBackend struct{}
+
+func (bandwidthBackend) Annotate(map[string]string, webhook.CustomItems) []string {
+	return nil
+}
+
+// ciliumBackend reads the egress bandwidth annotation of the CNI bandwidth plugin.
+type ciliumBackend struct{}
+
+func (ciliumBackend) Annotate(_ map[string]string, bandwidth webhook.CustomItems) []string {
+	return unsupported(BackendCilium, map[string]resource.Quantity{
+		common.IngressBandwidthAnnotation: bandwidth.Ingress,
+	})
+}
+
+// kubeOVNBackend sets the rate annotations of Kube-OVN, in whole Mbit/s.
+type kubeOVNBackend struct{}
+
+func (kubeOVNBackend) Annotate(an map[string]string, bandwidth webhook.CustomItems) []string {
+	var warnings []string
+	for _, r := range []struct {
+		key, rateKey string
+		rate         resource.Quantity
+	}{
+		{common.IngressBandwidthAnnotation, common.KubeOVNIngressRateAnnotation, bandwidth.Ingress},
+		{common.EgressBandwidthAnnotation, common.KubeOVNEgressRateAnnotation, bandwidth.Egress},
+	} {
+		if r.rate.IsZero() {
+			if _, ok := an[r.rateKey]; ok {
+				warnings = append(warnings, fmt.Sprintf("%s: not validated against the bandwidth policies, set %s instead",
+					policy.AnnotationPath(r.rateKey), r.key))
+			}
+			continue
+		}
+		// a rate of 0 lifts the limit in Kube-OVN, the rate is at least 1 Mbit/s
+		bits := r.rate.Value()
+		mbit := max(bits/1000000, 1)
+		if bits != mbit*1000000 {
+			warnings = append(warnings, fmt.Sprintf("%s: %s rounded to %d Mbit/s, the unit of %s",
+				policy.AnnotationPath(r.key), r.rate.String(), mbit, BackendKubeOVN))
+		}
+		an[r.rateKey] = strconv.FormatInt(mbit, 10)
+	}
+	return warnings
+}
+
+// unsupported returns a warning for every bandwidth set that the backend does not enforce.
+func unsupported(backend string, bandwidth map[string]resource.Quantity) []string {
+	var warnings []string
+	for _, key := range policy.PodKeys {
+		if q, ok := bandwidth[key]; ok && !q.IsZero() {
+			warnings = append(warnings, fmt.Sprintf("%s: not enforced by the %s bandwidth backend", policy.AnnotationPath(key), backend))
+		}
+	}
+	return warnings
+}
+
+// annotate sets the annotations of the CNI backend of the namespace of the pod, see Backend.
+func (a *PodAnnotator) annotate(ctx context.Context, pod *corev1.Pod) admission.Warnings {
+	var warnings admission.Warnings
+	name := a.Backend
+	ns := &corev1.Namespace{}
+	if err := a.Client.Get(ctx, client.ObjectKey{Name: pod.Namespace}, ns); err != nil {
+		customlimitrangelog.Info("Get Namespace Error", "namespace", pod.Namespace, "err", err)
+	} else if v, ok := ns.Annotations[common.BandwidthBackendAnnotation]; ok {
+		if _, known := Backends[v]; known {
+			name = v
+		} else {
+			warnings = append(warnings, fmt.Sprintf("namespace %s: %v: %q, using the backend of the cluster",
+				pod.Namespace, common.ErrUnknownBandwidthBackend, v))
+		}
+	}
+
+	backend, err := LookupBackend(name)
+	if err != nil {
+		customlimitrangelog.Info("Bandwidth backend Error", "backend", name, "err", err)
+		return append(warnings, err.Error())
+	}
+	return append(warnings, backend.Annotate(pod.Annotations, webhook.PodBandwidth(pod.Annotations))...)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+)
+
+func TestBackends(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	an := map[string]string{
+		common.IngressBandwidthAnnotation: "100M",
+		common.EgressBandwidthAnnotation:  "2500k",
+	}
+	bandwidth := webhook.PodBandwidth(an)
+
+	for _, name := range []string{"", BackendBandwidth, BackendMultus} {
+		b, err := LookupBackend(name)
+		assert.NoError(err)
+		out := map[string]string{}
+		assert.Empty(b.Annotate(out, bandwidth))
+		assert.Empty(out)
+	}
+
+	b, _ := LookupBackend(BackendCilium)
+	assert.Equal([]string{
+		"metadata.annotations[kubernetes.io/ingress-bandwidth]: not enforced by the cilium bandwidth backend",
+	}, b.Annotate(map[string]string{}, bandwidth))
+
+	b, _ = LookupBackend(BackendKubeOVN)
+	out := map[string]string{}
+	assert.Equal([]string{
+		"metadata.annotations[kubernetes.io/egress-bandwidth]: 2500k rounded to 2 Mbit/s, the unit of kube-ovn",
+	}, b.Annotate(out, bandwidth))
+	assert.Equal(map[string]string{
+		common.KubeOVNIngressRateAnnotation: "100",
+		common.KubeOVNEgressRateAnnotation:  "2",
+	}, out)
+
+	// a rate of 0 would lift the limit, a rate set by the user is replaced or reported
+	out = map[string]string{common.KubeOVNIngressRateAnnotation: "1000", common.KubeOVNEgressRateAnnotation: "1000"}
+	warnings := b.Annotate(out, webhook.PodBandwidth(map[string]string{common.EgressBandwidthAnnotation: "10k"}))
+	assert.Equal("1", out[common.KubeOVNEgressRateAnnotation])
+	assert.Equal("1000", out[common.KubeOVNIngressRateAnnotation])
+	assert.Len(warnings, 2)
+	assert.Contains(warnings[0], "not validated against the bandwidth policies, set kubernetes.io/ingress-bandwidth instead")
+
+	_, err := LookupBackend("calico")
+	assert.ErrorIs(err, common.ErrUnknownBandwidthBackend)
+}
+
+func TestDefaultBackend(t *testing.T) {
+	assert := assert.New(t)
+
+	clr := newLimitRangeWithMode(webhook.EnforcementModeEnforce)
+	ovn := newNamespace("test-b", nil)
+	ovn.Annotations = map[string]string{common.BandwidthBackendAnnotation: BackendKubeOVN}
+	unknown := newNamespace("test-c", nil)
+	unknown.Annotations = map[string]string{common.BandwidthBackendAnnotation: "calico"}
+	a := newAnnotator(newNamespace("test-a", nil), ovn, unknown, clr)
+	a.Backend = BackendCilium
+
+	// the backend of the cluster
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "test-a"}}
+	warnings, err := a.Default(context.Background(), pod)
+	assert.NoError(err)
+	assert.Equal("500M", pod.Annotations[common.IngressBandwidthAnnotation])
+	assert.Len(warnings, 1)
+	assert.Contains(warnings[0], "not enforced by the cilium bandwidth backend")
+
+	// the backend of the namespace
+	pod = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "test-b",
+		Annotations: map[string]string{common.EgressBandwidthAnnotation: "10G"}}}
+	warnings, err = a.Default(context.Background(), pod)
+	assert.NoError(err)
+	assert.Empty(warnings)
+	assert.Equal("10000", pod.Annotations[common.KubeOVNEgressRateAnnotation])
+
+	// an unknown backend falls back to the one of the cluster
+	pod = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "test-c",
+		Annotations: map[string]string{common.EgressBandwidthAnnotation: "10G"}}}
+	warnings, err = a.Default(context.Background(), pod)
+	assert.NoError(err)
+	assert.Len(warnings, 1)
+	assert.Contains(warnings[0], `namespace test-c: unknown bandwidth backend: "calico"`)
+	assert.NotContains(pod.Annotations, common.KubeOVNEgressRateAnnotation)
+}
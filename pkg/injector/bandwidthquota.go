@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+)
+
+// checkQuota returns an error when the pod does not fit in every BandwidthQuota of the namespace, summed from its pods.
+func (a *PodAnnotator) checkQuota(ctx context.Context, an map[string]string, name, namespace string) error {
+	requested := webhook.PodBandwidth(an)
+	if requested.Ingress.IsZero() && requested.Egress.IsZero() {
+		return nil
+	}
+
+	bql := &webhook.BandwidthQuotaList{}
+	if err := a.Client.List(ctx, bql, client.InNamespace(namespace)); err != nil {
+		customlimitrangelog.Info("Get BandwidthQuota Resource Error", "namespace", namespace, "err", err)
+		return fmt.Errorf("%w: list BandwidthQuota: %v", common.ErrMissingConfiguration, err)
+	}
+	if len(bql.Items) <= 0 {
+		return nil
+	}
+
+	pods := &corev1.PodList{}
+	if err := a.Client.List(ctx, pods, client.InNamespace(namespace)); err != nil {
+		customlimitrangelog.Info("List Pod Error", "namespace", namespace, "err", err)
+		return fmt.Errorf("%w: list Pod: %v", common.ErrMissingConfiguration, err)
+	}
+	used := webhook.BandwidthUsage(pods.Items, name)
+
+	var exceeded []string
+	for i := range bql.Items {
+		msgs := bql.Items[i].Exceeded(used, requested)
+		if len(msgs) == 0 {
+			continue
+		}
+		msg := fmt.Sprintf("BandwidthQuota %s/%s: %s", namespace, bql.Items[i].Name, strings.Join(msgs, ", "))
+		customlimitrangelog.Info("bandwidth quota exceeded", "pod", name, "quota", msg)
+		if recorder := a.recorder(ctx); recorder != nil {
+			recorder.Event(&bql.Items[i], corev1.EventTypeWarning, common.ReasonBandwidthQuotaExceeded, msg)
+		}
+		exceeded = append(exceeded, msg)
+	}
+	if len(exceeded) > 0 {
+		return fmt.Errorf("%w: %s", common.ErrBandwidthQuotaExceeded, strings.Join(exceeded, "; "))
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+)
+
+func TestDefaultBandwidthQuota(t *testing.T) {
+	assert := assert.New(t)
+
+	a := newAnnotator(
+		newNamespace("test-a", nil),
+		&webhook.CustomLimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: "local", Namespace: "test-a"},
+			Spec:       webhook.CustomLimitRangeSpec{LRange: newLimitRange("1G", "100M", "500M")},
+		},
+		&webhook.BandwidthQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "test-a"},
+			Spec:       webhook.BandwidthQuotaSpec{Hard: webhook.CustomItems{Ingress: resource.MustParse("1G")}},
+		},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: "test-a",
+			Annotations: map[string]string{common.IngressBandwidthAnnotation: "400M"}}},
+	)
+	ctx := context.Background()
+	newPod := func(name string, an map[string]string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-a", Annotations: an}}
+	}
+
+	// the defaulted 500M fits in the remaining 600M
+	pod := newPod("fits", nil)
+	_, err := a.Default(ctx, pod)
+	assert.Nil(err)
+	assert.Equal("500M", pod.Annotations[common.IngressBandwidthAnnotation])
+
+	_, err = a.Default(ctx, newPod("too-large", map[string]string{common.IngressBandwidthAnnotation: "700M"}))
+	assert.ErrorIs(err, common.ErrBandwidthQuotaExceeded)
+
+	// an update of a running pod does not count it twice
+	_, err = a.Default(ctx, newPod("running", map[string]string{common.IngressBandwidthAnnotation: "900M"}))
+	assert.Nil(err)
+}
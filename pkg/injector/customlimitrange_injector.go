@@ -18,32 +18,85 @@ package injector
 
 import (
 	"context"
+	"encoding/json"
+	goerrors "errors"
 	"fmt"
+	"net/http"
+	"slices"
 
+	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/policy"
 	"github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
 )
 
 // log is for logging in this package.
 var customlimitrangelog = logf.Log.WithName("customlimitrange-injector")
 
+// PodAnnotator validates the bandwidth annotations of incoming pods and injects the defaults of the policies.
 type PodAnnotator struct {
-	Client client.Client
+	// Client reads the policies. In the manager it is the informer cache, see SetupPolicyCache.
+	Client   client.Reader
+	Decoder  admission.Decoder
+	Recorder record.EventRecorder
+	// Backend names the CNI backend of the cluster, see Backends. Empty is the CNI bandwidth plugin.
+	Backend string
 }
 
-// PodAnnotator adds an annotation to every incoming pods.
-func (a *PodAnnotator) Default(ctx context.Context, obj runtime.Object) error {
+var _ admission.Handler = &PodAnnotator{}
+
+// Handle implements admission.Handler for pods.
+func (a *PodAnnotator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation == admissionv1.Delete {
+		return admission.Allowed("")
+	}
+
+	pod := &corev1.Pod{}
+	if err := a.Decoder.Decode(req, pod); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	warnings, err := a.Default(admission.NewContextWithRequest(ctx, req), pod)
+	var ae *annotationError
+	if goerrors.As(err, &ae) {
+		name := pod.Name
+		if name == "" {
+			name = pod.GenerateName
+		}
+		status := ae.status(corev1.SchemeGroupVersion.WithKind("Pod").GroupKind(), name).ErrStatus
+		return admission.Response{AdmissionResponse: admissionv1.AdmissionResponse{
+			Allowed:  false,
+			Result:   &status,
+			Warnings: warnings,
+		}}
+	}
+	if err != nil {
+		return admission.Denied(err.Error()).WithWarnings(warnings...)
+	}
+
+	marshalled, err := json.Marshal(pod)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshalled).WithWarnings(warnings...)
+}
+
+// Default adds the bandwidth annotations to the pod, updates only validate the annotations they change.
+func (a *PodAnnotator) Default(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
 	customlimitrangelog.Info("PodAnnotator", "obj", obj)
 	pod, ok := obj.(*corev1.Pod)
 	if !ok {
-		return fmt.Errorf("expected a Pod but got a %T", obj)
+		return nil, fmt.Errorf("expected a Pod but got a %T", obj)
 	}
 
 	if pod.Annotations == nil {
@@ -51,75 +104,243 @@ func (a *PodAnnotator) Default(ctx context.Context, obj runtime.Object) error {
 	}
 
 	ns := pod.Namespace
+	req, reqErr := admission.RequestFromContext(ctx)
+	if reqErr == nil && req.Namespace != "" {
+		ns = req.Namespace
+	}
 	customlimitrangelog.Info("request", "pod", pod, "namespace", ns)
-
 	if ns == "" {
-		ns = "default"
+		return nil, fmt.Errorf("%w: pod %s has no namespace", common.ErrInvalidAdmissionReviewObj, pod.Name)
 	}
+	pod.Namespace = ns
 
-	if val, ok := pod.Annotations["customlimitrange.kubernetes.io/limited"]; ok && val == "disable" {
-		return nil
+	if policy.Disabled(pod.Annotations) {
+		return nil, nil
 	}
 
-	an, err := a.ConfigAnnotation(pod.Annotations, ns)
+	if reqErr == nil && req.Operation == admissionv1.Update && len(req.OldObject.Raw) > 0 {
+		old := &corev1.Pod{}
+		if err := json.Unmarshal(req.OldObject.Raw, old); err != nil {
+			return nil, fmt.Errorf("decode old Pod: %w", err)
+		}
+		return a.update(ctx, old, pod)
+	}
+
+	an, warnings, err := a.ConfigAnnotation(ctx, pod.Annotations, pod.Labels, ns)
 	if err != nil {
-		return err
+		return warnings, err
+	}
+
+	if err := a.checkQuota(ctx, an, pod.Name, ns); err != nil {
+		return warnings, err
+	}
+
+	an, networkWarnings, err := a.ConfigNetworks(ctx, an, ns)
+	warnings = append(warnings, networkWarnings...)
+	if err != nil {
+		return warnings, err
 	}
 
 	pod.Annotations = an
-	pod.Namespace = ns
+	warnings = append(warnings, a.annotate(ctx, pod)...)
 
 	customlimitrangelog.Info("patch", "pod", pod, "namespace", ns)
 
-	return nil
+	return warnings, nil
+}
+
+// update validates the bandwidth annotations changed by an update of a pod, see ErrBandwidthAnnotationChanged.
+func (a *PodAnnotator) update(ctx context.Context, old, pod *corev1.Pod) (admission.Warnings, error) {
+	for _, key := range policy.ProvenanceAnnotations {
+		if v, ok := old.Annotations[key]; ok {
+			pod.Annotations[key] = v
+		} else {
+			delete(pod.Annotations, key)
+		}
+	}
+
+	changed := changedAnnotations(old.Annotations, pod.Annotations)
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	if old.Spec.NodeName != "" {
+		policies, _, err := PoliciesFor(ctx, a.Client, pod.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		const detail = "takes effect only when the pod sandbox is recreated, recreate the pod instead"
+		if len(policies) > 0 && policy.MergeModes(policies) == policy.ModeEnforce {
+			errs := make(field.ErrorList, 0, len(changed))
+			for _, key := range changed {
+				errs = append(errs, field.Forbidden(policy.AnnotationPath(key), "changed on a pod bound to a node: "+detail))
+			}
+			return nil, &annotationError{reason: common.ErrBandwidthAnnotationChanged, errs: errs}
+		}
+		warnings := make(admission.Warnings, 0, len(changed))
+		for _, key := range changed {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", policy.AnnotationPath(key), detail))
+		}
+		return warnings, nil
+	}
+
+	an, warnings, err := a.ConfigAnnotation(ctx, pod.Annotations, pod.Labels, pod.Namespace)
+	if err != nil {
+		return warnings, err
+	}
+	if err := a.checkQuota(ctx, an, pod.Name, pod.Namespace); err != nil {
+		return warnings, err
+	}
+	for k, v := range an {
+		if _, set := pod.Annotations[k]; !set && slices.Contains(policy.PodKeys, k) {
+			continue
+		}
+		if slices.Contains(policy.ProvenanceAnnotations, k) {
+			continue
+		}
+		pod.Annotations[k] = v
+	}
+	return append(warnings, a.annotate(ctx, pod)...), nil
+}
+
+// changedAnnotations returns the bandwidth annotations that differ between old and updated.
+func changedAnnotations(old, updated map[string]string) []string {
+	var changed []string
+	for _, key := range policy.PodKeys {
+		if old[key] != updated[key] {
+			changed = append(changed, key)
+		}
+	}
+	return changed
+}
+
+// recorder returns the event recorder, or nil in a dry-run request, which must not have side effects.
+func (a *PodAnnotator) recorder(ctx context.Context) record.EventRecorder {
+	if req, err := admission.RequestFromContext(ctx); err == nil && req.DryRun != nil && *req.DryRun {
+		return nil
+	}
+	return a.Recorder
+}
+
+// ConfigAnnotation evaluates the bandwidth annotations against the policies of the namespace, see policy.Evaluate.
+func (a *PodAnnotator) ConfigAnnotation(ctx context.Context, an map[string]string, podLabels map[string]string, namespace string) (map[string]string, admission.Warnings, error) {
+	policies, objects, err := PoliciesFor(ctx, a.Client, namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res := policy.Evaluate(policies, podLabels, an)
+	var warnings admission.Warnings
+	switch res.Decision {
+	case policy.Deny:
+		return nil, nil, &annotationError{reason: res.Cause, errs: res.Errors}
+	case policy.Warn:
+		warnings = res.Reasons
+	}
+	if res.Mode == policy.ModeAudit || res.Mode == policy.ModeDryRun {
+		audit(a.recorder(ctx), res, objects)
+	}
+	return policy.WithProvenance(policies, res), warnings, nil
+}
+
+// audit logs the violations and records them as events on the policies.
+func audit(recorder record.EventRecorder, res policy.Result, objects []runtime.Object) {
+	for _, v := range res.Violations {
+		customlimitrangelog.Info("bandwidth out of range", "policy", res.Source, "mode", res.Mode, "violation", v.String())
+		if recorder == nil {
+			continue
+		}
+		for _, obj := range objects {
+			recorder.Event(obj, corev1.EventTypeWarning, common.ReasonBandwidthOutOfRange, v.String())
+		}
+	}
 }
 
-func (a *PodAnnotator) ConfigAnnotation(an map[string]string, namespace string) (map[string]string, error) {
+// PoliciesFor returns the policies in effect in the namespace with the objects they come from.
+func PoliciesFor(ctx context.Context, c client.Reader, namespace string) ([]policy.Policy, []runtime.Object, error) {
 	clrl := &webhook.CustomLimitRangeList{}
-	err := a.Client.List(context.Background(), clrl, client.InNamespace(namespace))
+	err := c.List(ctx, clrl, client.InNamespace(namespace))
 	if err != nil {
-		customlimitrangelog.Info("Get CustomLimitRange Resource Error", "namespace", namespace, "resource name", common.WebhookName)
+		customlimitrangelog.Info("Get CustomLimitRange Resource Error", "namespace", namespace, "resource name", common.WebhookName, "err", err)
 		if errors.IsNotFound(err) {
-			return an, nil
+			return nil, nil, nil
 		}
-		return nil, common.ErrMissingConfiguration
+		return nil, nil, fmt.Errorf("%w: list CustomLimitRange: %v", common.ErrMissingConfiguration, err)
 	}
 
-	if len(clrl.Items) > 1 {
-		customlimitrangelog.Info("Namespace has more than one CustomLimitRange Resource", "count", len(clrl.Items))
-		return nil, common.ErrInvalidCustomLimitRangeCountMoreThanOne
-	} else if len(clrl.Items) <= 0 {
-		// Namespace not found CustomLimitRange Resource
+	if len(clrl.Items) <= 0 {
+		// Namespace not found CustomLimitRange Resource, fall back to ClusterCustomLimitRange
 		customlimitrangelog.Info("Namespace not found CustomLimitRange Resource")
-		return an, nil
-	} else {
-		customlimitrangelog.Info("PodAnnotator get CustomLimitRange", "CustomLimitRange", clrl.Items[0].Spec)
-		ingress, ok1 := an["kubernetes.io/ingress-bandwidth"]
-		if ok1 {
-			ig := resource.MustParse(ingress)
-			if (!clrl.Items[0].Spec.LRange.Max.Ingress.IsZero() && ig.Value() > clrl.Items[0].Spec.LRange.Max.Ingress.Value()) ||
-				(!clrl.Items[0].Spec.LRange.Min.Ingress.IsZero() && ig.Value() < clrl.Items[0].Spec.LRange.Min.Ingress.Value()) {
-				return nil, common.ErrInvalidPodSettingBandwidthMaxMin
-			}
-		} else {
-			if !clrl.Items[0].Spec.LRange.Default.Ingress.IsZero() {
-				an["kubernetes.io/ingress-bandwidth"] = clrl.Items[0].Spec.LRange.Default.Ingress.String()
-			}
+		cclr, err := clusterCustomLimitRange(ctx, c, namespace)
+		if err != nil {
+			return nil, nil, err
 		}
-		egress, ok2 := an["kubernetes.io/egress-bandwidth"]
-		if ok2 {
-			eg := resource.MustParse(egress)
-			if (!clrl.Items[0].Spec.LRange.Max.Egress.IsZero() && eg.Value() > clrl.Items[0].Spec.LRange.Max.Egress.Value()) ||
-				(!clrl.Items[0].Spec.LRange.Min.Egress.IsZero() && eg.Value() < clrl.Items[0].Spec.LRange.Min.Egress.Value()) {
-				return nil, common.ErrInvalidPodSettingBandwidthMaxMin
-			}
-		} else {
-			if !clrl.Items[0].Spec.LRange.Default.Egress.IsZero() {
-				an["kubernetes.io/egress-bandwidth"] = clrl.Items[0].Spec.LRange.Default.Egress.String()
-			}
+		if cclr == nil {
+			return nil, nil, nil
+		}
+		customlimitrangelog.Info("PodAnnotator get ClusterCustomLimitRange", "ClusterCustomLimitRange", cclr.Name)
+		return []policy.Policy{cclr.Policy()}, []runtime.Object{cclr}, nil
+	}
+
+	customlimitrangelog.Info("PodAnnotator get CustomLimitRange", "count", len(clrl.Items))
+	objects := make([]runtime.Object, 0, len(clrl.Items))
+	for i := range clrl.Items {
+		objects = append(objects, &clrl.Items[i])
+	}
+	return webhook.Policies(clrl.Items), objects, nil
+}
+
+// clusterCustomLimitRange returns the first ClusterCustomLimitRange by name selecting the namespace, or nil.
+func clusterCustomLimitRange(ctx context.Context, c client.Reader, namespace string) (*webhook.ClusterCustomLimitRange, error) {
+	cclrl := &webhook.ClusterCustomLimitRangeList{}
+	if err := c.List(ctx, cclrl); err != nil {
+		customlimitrangelog.Info("Get ClusterCustomLimitRange Resource Error", "err", err)
+		if errors.IsNotFound(err) {
+			return nil, nil
 		}
+		return nil, fmt.Errorf("%w: list ClusterCustomLimitRange: %v", common.ErrMissingConfiguration, err)
+	}
+	if len(cclrl.Items) <= 0 {
+		return nil, nil
+	}
 
+	ns := &corev1.Namespace{}
+	if err := c.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		customlimitrangelog.Info("Get Namespace Error", "namespace", namespace, "err", err)
+		return nil, fmt.Errorf("%w: get Namespace %s: %v", common.ErrMissingConfiguration, namespace, err)
 	}
 
-	return an, nil
+	policies := make([]policy.Policy, 0, len(cclrl.Items))
+	for i := range cclrl.Items {
+		policies = append(policies, cclrl.Items[i].Policy())
+	}
+	applicable := policy.Applicable(policies, namespace, ns.Labels)
+	if len(applicable) == 0 {
+		return nil, nil
+	}
+	for i := range cclrl.Items {
+		if cclrl.Items[i].Name == applicable[0].Name {
+			return &cclrl.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// annotationError rejects the bandwidth annotations of a pod, with one field error per annotation.
+type annotationError struct {
+	reason error
+	errs   field.ErrorList
+}
+
+func (e *annotationError) Error() string {
+	return fmt.Sprintf("%v: %v", e.reason, e.errs.ToAggregate())
+}
+
+func (e *annotationError) Unwrap() error {
+	return e.reason
+}
+
+// status returns the error as an Invalid status for the named object.
+func (e *annotationError) status(kind schema.GroupKind, name string) *errors.StatusError {
+	return errors.NewInvalid(kind, name, e.errs)
 }
@@ -0,0 +1,498 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+)
+
+func newScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = webhook.AddToScheme(scheme)
+	return scheme
+}
+
+func newAnnotator(objs ...client.Object) *PodAnnotator {
+	return &PodAnnotator{Client: fake.NewClientBuilder().WithScheme(newScheme()).WithObjects(objs...).Build()}
+}
+
+func newNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func newLimitRange(max, min, def string) webhook.LimitRange {
+	return webhook.LimitRange{
+		Type: "Pod",
+		BandwidthRange: webhook.BandwidthRange{
+			Max:     webhook.CustomItems{Ingress: resource.MustParse(max), Egress: resource.MustParse(max)},
+			Min:     webhook.CustomItems{Ingress: resource.MustParse(min), Egress: resource.MustParse(min)},
+			Default: webhook.CustomItems{Ingress: resource.MustParse(def), Egress: resource.MustParse(def)},
+		},
+	}
+}
+
+func TestConfigAnnotationNamespaced(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	a := newAnnotator(
+		newNamespace("test-a", nil),
+		&webhook.CustomLimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: "local", Namespace: "test-a"},
+			Spec:       webhook.CustomLimitRangeSpec{LRange: newLimitRange("1G", "100M", "500M")},
+		},
+	)
+
+	an, _, err := a.ConfigAnnotation(ctx, map[string]string{}, nil, "test-a")
+	assert.Nil(err)
+	assert.Equal("500M", an[common.IngressBandwidthAnnotation])
+	assert.Equal("500M", an[common.EgressBandwidthAnnotation])
+
+	an, _, err = a.ConfigAnnotation(ctx, map[string]string{common.IngressBandwidthAnnotation: "200M"}, nil, "test-a")
+	assert.Nil(err)
+	assert.Equal("200M", an[common.IngressBandwidthAnnotation])
+
+	_, _, err = a.ConfigAnnotation(ctx, map[string]string{common.EgressBandwidthAnnotation: "10G"}, nil, "test-a")
+	assert.ErrorIs(err, common.ErrInvalidPodSettingBandwidthMaxMin)
+}
+
+func TestConfigAnnotationClusterFallback(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	a := newAnnotator(
+		newNamespace("tenant-a", map[string]string{"tenant": "true"}),
+		newNamespace("tenant-b", map[string]string{"tenant": "true"}),
+		newNamespace("other", nil),
+		&webhook.CustomLimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: "local", Namespace: "tenant-b"},
+			Spec:       webhook.CustomLimitRangeSpec{LRange: newLimitRange("1G", "100M", "500M")},
+		},
+		&webhook.ClusterCustomLimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: "tenant"},
+			Spec: webhook.ClusterCustomLimitRangeSpec{
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "true"}},
+				LRange:            newLimitRange("100M", "1M", "10M"),
+			},
+		},
+	)
+
+	an, _, err := a.ConfigAnnotation(ctx, map[string]string{}, nil, "tenant-a")
+	assert.Nil(err)
+	assert.Equal("10M", an[common.IngressBandwidthAnnotation])
+
+	_, _, err = a.ConfigAnnotation(ctx, map[string]string{common.IngressBandwidthAnnotation: "200M"}, nil, "tenant-a")
+	assert.ErrorIs(err, common.ErrInvalidPodSettingBandwidthMaxMin)
+
+	// the local CustomLimitRange takes precedence over the cluster policy
+	an, _, err = a.ConfigAnnotation(ctx, map[string]string{}, nil, "tenant-b")
+	assert.Nil(err)
+	assert.Equal("500M", an[common.IngressBandwidthAnnotation])
+
+	an, _, err = a.ConfigAnnotation(ctx, map[string]string{}, nil, "other")
+	assert.Nil(err)
+	assert.Empty(an)
+}
+
+func TestConfigAnnotationMerged(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	a := newAnnotator(
+		newNamespace("test-a", nil),
+		&webhook.CustomLimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "test-a"},
+			Spec:       webhook.CustomLimitRangeSpec{LRange: newLimitRange("1G", "100M", "500M")},
+		},
+		&webhook.CustomLimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "test-a"},
+			Spec:       webhook.CustomLimitRangeSpec{LRange: newLimitRange("800M", "10M", "300M"), Priority: 1},
+		},
+	)
+
+	an, _, err := a.ConfigAnnotation(ctx, map[string]string{}, nil, "test-a")
+	assert.Nil(err)
+	assert.Equal("300M", an[common.IngressBandwidthAnnotation])
+
+	an, _, err = a.ConfigAnnotation(ctx, map[string]string{common.IngressBandwidthAnnotation: "50M"}, nil, "test-a")
+	assert.Nil(err)
+	assert.Equal("50M", an[common.IngressBandwidthAnnotation])
+
+	_, _, err = a.ConfigAnnotation(ctx, map[string]string{common.IngressBandwidthAnnotation: "900M"}, nil, "test-a")
+	assert.ErrorIs(err, common.ErrInvalidPodSettingBandwidthMaxMin)
+	// the error names the annotation, the value, the bound and the policy that set it
+	assert.ErrorContains(err, `metadata.annotations[kubernetes.io/ingress-bandwidth]: Invalid value: "900M": must be at most 800M, the max set by CustomLimitRange test-a/b`)
+
+	_, _, err = a.ConfigAnnotation(ctx, map[string]string{common.EgressBandwidthAnnotation: "1M"}, nil, "test-a")
+	assert.ErrorContains(err, "must be at least 10M, the min set by CustomLimitRange test-a/b")
+}
+
+func TestConfigAnnotationInvalidValue(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	// without any policy, pods are admitted unchanged
+	a := newAnnotator(newNamespace("test-a", nil))
+	in := map[string]string{common.IngressBandwidthAnnotation: "10Gbps"}
+	an, _, err := a.ConfigAnnotation(ctx, in, nil, "test-a")
+	assert.NoError(err)
+	assert.Equal(in, an)
+
+	a = newAnnotator(newNamespace("test-a", nil), &webhook.CustomLimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "test-a"},
+		Spec: webhook.CustomLimitRangeSpec{
+			LRange:          newLimitRange("1G", "100M", "500M"),
+			EnforcementMode: webhook.EnforcementModeClamp,
+		},
+	})
+	for _, val := range []string{"10Gbps", "", "-1M"} {
+		assert.NotPanics(func() {
+			_, _, err := a.ConfigAnnotation(ctx, map[string]string{common.IngressBandwidthAnnotation: val}, nil, "test-a")
+			assert.ErrorIs(err, common.ErrInvalidPodBandwidthAnnotation, val)
+			assert.ErrorContains(err, "metadata.annotations[kubernetes.io/ingress-bandwidth]", val)
+		})
+	}
+}
+
+func TestConfigAnnotationRules(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	clr := &webhook.CustomLimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "test-a"},
+		Spec: webhook.CustomLimitRangeSpec{
+			LRange: newLimitRange("1G", "100M", "500M"),
+			Rules: []webhook.LimitRangeRule{
+				{
+					Name:        "database",
+					PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+					BandwidthRange: webhook.BandwidthRange{
+						Max:     webhook.CustomItems{Ingress: resource.MustParse("10G")},
+						Default: webhook.CustomItems{Ingress: resource.MustParse("5G")},
+					},
+				},
+			},
+		},
+	}
+	a := newAnnotator(newNamespace("test-a", nil), clr)
+
+	an, _, err := a.ConfigAnnotation(ctx, map[string]string{}, map[string]string{"app": "db"}, "test-a")
+	assert.Nil(err)
+	assert.Equal("5G", an[common.IngressBandwidthAnnotation])
+	_, ok := an[common.EgressBandwidthAnnotation]
+	assert.False(ok)
+
+	an, _, err = a.ConfigAnnotation(ctx, map[string]string{common.IngressBandwidthAnnotation: "8G"}, map[string]string{"app": "db"}, "test-a")
+	assert.Nil(err)
+	assert.Equal("8G", an[common.IngressBandwidthAnnotation])
+
+	_, _, err = a.ConfigAnnotation(ctx, map[string]string{common.IngressBandwidthAnnotation: "8G"}, map[string]string{"app": "web"}, "test-a")
+	assert.ErrorIs(err, common.ErrInvalidPodSettingBandwidthMaxMin)
+}
+
+func TestConfigAnnotationEnforcementMode(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	clr := &webhook.CustomLimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "test-a"},
+		Spec:       webhook.CustomLimitRangeSpec{LRange: newLimitRange("1G", "100M", "500M")},
+	}
+	out := map[string]string{common.IngressBandwidthAnnotation: "10G"}
+
+	clr.Spec.EnforcementMode = webhook.EnforcementModeWarn
+	a := newAnnotator(newNamespace("test-a", nil), clr.DeepCopy())
+	an, warnings, err := a.ConfigAnnotation(ctx, out, nil, "test-a")
+	assert.Nil(err)
+	assert.Len(warnings, 1)
+	assert.Equal("10G", an[common.IngressBandwidthAnnotation])
+	assert.Equal("500M", an[common.EgressBandwidthAnnotation])
+
+	clr.Spec.EnforcementMode = webhook.EnforcementModeAudit
+	recorder := record.NewFakeRecorder(10)
+	a = newAnnotator(newNamespace("test-a", nil), clr.DeepCopy())
+	a.Recorder = recorder
+	an, warnings, err = a.ConfigAnnotation(ctx, out, nil, "test-a")
+	assert.Nil(err)
+	assert.Empty(warnings)
+	assert.Equal("500M", an[common.EgressBandwidthAnnotation])
+	assert.Len(recorder.Events, 1)
+
+	clr.Spec.EnforcementMode = webhook.EnforcementModeDryRun
+	a = newAnnotator(newNamespace("test-a", nil), clr.DeepCopy())
+	an, warnings, err = a.ConfigAnnotation(ctx, out, nil, "test-a")
+	assert.Nil(err)
+	assert.Len(warnings, 2)
+	_, ok := an[common.EgressBandwidthAnnotation]
+	assert.False(ok)
+
+	clr.Spec.EnforcementMode = webhook.EnforcementModeClamp
+	a = newAnnotator(newNamespace("test-a", nil), clr.DeepCopy())
+	an, warnings, err = a.ConfigAnnotation(ctx, map[string]string{
+		common.IngressBandwidthAnnotation: "10G",
+		common.EgressBandwidthAnnotation:  "1M",
+	}, nil, "test-a")
+	assert.Nil(err)
+	assert.Len(warnings, 2)
+	assert.Equal("1G", an[common.IngressBandwidthAnnotation])
+	assert.Equal("10G", an[common.RequestedIngressBandwidthAnnotation])
+	assert.Equal("100M", an[common.EgressBandwidthAnnotation])
+	assert.Equal("1M", an[common.RequestedEgressBandwidthAnnotation])
+
+	// the strictest mode of the namespace wins
+	warn := clr.DeepCopy()
+	warn.Name = "b"
+	warn.Spec.EnforcementMode = webhook.EnforcementModeWarn
+	enforce := clr.DeepCopy()
+	enforce.Spec.EnforcementMode = ""
+	a = newAnnotator(newNamespace("test-a", nil), warn, enforce)
+	_, _, err = a.ConfigAnnotation(ctx, out, nil, "test-a")
+	assert.ErrorIs(err, common.ErrInvalidPodSettingBandwidthMaxMin)
+}
+
+func TestConfigAnnotationBurst(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	// a range of the pod network stored before bursts were forbidden there
+	lr := newLimitRange("1G", "100M", "500M")
+	lr.Max.IngressBurst = resource.MustParse("100M")
+	lr.Default.IngressBurst = resource.MustParse("10M")
+	clr := &webhook.CustomLimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "test-a"},
+		Spec:       webhook.CustomLimitRangeSpec{LRange: lr},
+	}
+	a := newAnnotator(newNamespace("test-a", nil), clr)
+
+	// the container runtimes do not read a burst, none is injected or validated
+	an, _, err := a.ConfigAnnotation(ctx, map[string]string{"kubernetes.io/ingress-burst": "1G"}, nil, "test-a")
+	assert.Nil(err)
+	assert.Equal("1G", an["kubernetes.io/ingress-burst"])
+	assert.NotContains(an, common.IngressBurstKey)
+	assert.Equal("kubernetes.io/ingress-bandwidth,kubernetes.io/egress-bandwidth", an[common.ProvenanceDefaultedAnnotation])
+}
+
+func TestHandle(t *testing.T) {
+	assert := assert.New(t)
+
+	clr := &webhook.CustomLimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "test-a"},
+		Spec: webhook.CustomLimitRangeSpec{
+			LRange:          newLimitRange("1G", "100M", "500M"),
+			EnforcementMode: webhook.EnforcementModeWarn,
+		},
+	}
+	a := newAnnotator(newNamespace("test-a", nil), clr)
+	a.Decoder = admission.NewDecoder(newScheme())
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "nginx",
+			Namespace:   "test-a",
+			Annotations: map[string]string{common.IngressBandwidthAnnotation: "10G"},
+		},
+	}
+	raw, err := json.Marshal(pod)
+	assert.Nil(err)
+
+	resp := a.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Namespace: "test-a",
+		Object:    runtime.RawExtension{Raw: raw},
+	}})
+	assert.True(resp.Allowed)
+	assert.Len(resp.Warnings, 1)
+	// the egress default and the provenance annotations
+	assert.Len(resp.Patches, 4)
+
+	clr.Spec.EnforcementMode = webhook.EnforcementModeEnforce
+	a = newAnnotator(newNamespace("test-a", nil), clr)
+	a.Decoder = admission.NewDecoder(newScheme())
+	resp = a.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Namespace: "test-a",
+		Object:    runtime.RawExtension{Raw: raw},
+	}})
+	assert.False(resp.Allowed)
+	assert.Equal(int32(http.StatusUnprocessableEntity), resp.Result.Code)
+	assert.Equal(metav1.StatusReasonInvalid, resp.Result.Reason)
+	assert.Len(resp.Result.Details.Causes, 1)
+	assert.Equal("metadata.annotations[kubernetes.io/ingress-bandwidth]", resp.Result.Details.Causes[0].Field)
+	assert.Contains(resp.Result.Details.Causes[0].Message, "CustomLimitRange test-a/a")
+}
+
+func podRequest(op admissionv1.Operation, pod, old *corev1.Pod) admission.Request {
+	req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: op,
+		Namespace: "test-a",
+		Name:      pod.Name,
+	}}
+	req.Object.Raw, _ = json.Marshal(pod)
+	if old != nil {
+		req.OldObject.Raw, _ = json.Marshal(old)
+	}
+	return req
+}
+
+func TestHandleNamespaceFromRequest(t *testing.T) {
+	assert := assert.New(t)
+
+	clr := &webhook.CustomLimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "test-a"},
+		Spec:       webhook.CustomLimitRangeSpec{LRange: newLimitRange("1G", "100M", "500M")},
+	}
+	a := newAnnotator(newNamespace("test-a", nil), clr)
+	a.Decoder = admission.NewDecoder(newScheme())
+
+	// the pod has no namespace, the policies of the namespace of the request apply
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{GenerateName: "nginx-"}}
+	resp := a.Handle(context.Background(), podRequest(admissionv1.Create, pod, nil))
+	assert.True(resp.Allowed)
+	paths := map[string]interface{}{}
+	for _, p := range resp.Patches {
+		paths[p.Path] = p.Value
+	}
+	assert.Equal("test-a", paths["/metadata/namespace"])
+	assert.Equal(map[string]interface{}{
+		common.IngressBandwidthAnnotation:    "500M",
+		common.EgressBandwidthAnnotation:     "500M",
+		common.ProvenancePoliciesAnnotation:  "test-a/a@0",
+		common.ProvenanceDefaultedAnnotation: common.IngressBandwidthAnnotation + "," + common.EgressBandwidthAnnotation,
+	}, paths["/metadata/annotations"])
+
+	_, err := a.Default(context.Background(), &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx"}})
+	assert.ErrorIs(err, common.ErrInvalidAdmissionReviewObj)
+}
+
+func TestHandleUpdate(t *testing.T) {
+	assert := assert.New(t)
+
+	clr := &webhook.CustomLimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "test-a"},
+		Spec:       webhook.CustomLimitRangeSpec{LRange: newLimitRange("1G", "100M", "500M")},
+	}
+	a := newAnnotator(newNamespace("test-a", nil), clr)
+	a.Decoder = admission.NewDecoder(newScheme())
+
+	// a pod created before the policy is neither defaulted nor rejected when its bandwidth is unchanged
+	legacy := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "test-a",
+		Annotations: map[string]string{common.IngressBandwidthAnnotation: "10G"}}}
+	legacy.Spec.NodeName = "node-1"
+	relabeled := legacy.DeepCopy()
+	relabeled.Labels = map[string]string{"app": "web"}
+	resp := a.Handle(context.Background(), podRequest(admissionv1.Update, relabeled, legacy))
+	assert.True(resp.Allowed)
+	assert.Empty(resp.Patches)
+
+	// changing the bandwidth of a pod bound to a node is rejected in enforce mode
+	running := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: "test-a",
+		Annotations: map[string]string{common.IngressBandwidthAnnotation: "500M"}}}
+	running.Spec.NodeName = "node-1"
+	changed := running.DeepCopy()
+	changed.Annotations[common.IngressBandwidthAnnotation] = "600M"
+	resp = a.Handle(context.Background(), podRequest(admissionv1.Update, changed, running))
+	assert.False(resp.Allowed)
+	assert.Equal(metav1.StatusReasonInvalid, resp.Result.Reason)
+	assert.Equal("metadata.annotations[kubernetes.io/ingress-bandwidth]", resp.Result.Details.Causes[0].Field)
+	assert.Equal(metav1.CauseTypeForbidden, resp.Result.Details.Causes[0].Type)
+
+	// and only returns a warning in the other modes
+	clr.Spec.EnforcementMode = webhook.EnforcementModeWarn
+	a = newAnnotator(newNamespace("test-a", nil), clr)
+	a.Decoder = admission.NewDecoder(newScheme())
+	resp = a.Handle(context.Background(), podRequest(admissionv1.Update, changed, running))
+	assert.True(resp.Allowed)
+	assert.Len(resp.Warnings, 1)
+	assert.Contains(resp.Warnings[0], "sandbox is recreated")
+
+	// a pod not bound yet is validated as on creation, without injecting the defaults
+	pending := running.DeepCopy()
+	pending.Spec.NodeName = ""
+	big := pending.DeepCopy()
+	big.Annotations[common.IngressBandwidthAnnotation] = "10G"
+	resp = a.Handle(context.Background(), podRequest(admissionv1.Update, big, pending))
+	assert.True(resp.Allowed)
+	assert.Len(resp.Warnings, 1)
+	assert.Empty(resp.Patches)
+
+	// the provenance annotations are kept as set on creation
+	admitted := running.DeepCopy()
+	admitted.Annotations[common.ProvenancePoliciesAnnotation] = "test-a/a@1"
+	forged := admitted.DeepCopy()
+	forged.Annotations[common.ProvenancePoliciesAnnotation] = "test-a/b@1"
+	forged.Annotations[common.ProvenanceDefaultedAnnotation] = common.IngressBandwidthAnnotation
+	resp = a.Handle(context.Background(), podRequest(admissionv1.Update, forged, admitted))
+	assert.True(resp.Allowed)
+	paths := map[string]interface{}{}
+	for _, p := range resp.Patches {
+		paths[p.Path] = p.Value
+	}
+	assert.Equal(map[string]interface{}{
+		"/metadata/annotations/customlimitrange.kubernetes.io~1policies":              "test-a/a@1",
+		"/metadata/annotations/customlimitrange.kubernetes.io~1defaulted-annotations": nil,
+	}, paths)
+}
+
+func TestConfigAnnotationProvenance(t *testing.T) {
+	assert := assert.New(t)
+
+	clr := &webhook.CustomLimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "test-a", Generation: 3},
+		Spec:       webhook.CustomLimitRangeSpec{LRange: newLimitRange("1G", "100M", "500M")},
+	}
+	ctx := context.Background()
+	a := newAnnotator(newNamespace("test-a", nil), clr)
+	an, _, err := a.ConfigAnnotation(ctx, map[string]string{
+		common.IngressBandwidthAnnotation:   "200M",
+		common.ProvenancePoliciesAnnotation: "forged",
+	}, nil, "test-a")
+	assert.Nil(err)
+	assert.Equal("test-a/a@3", an[common.ProvenancePoliciesAnnotation])
+	assert.Equal(common.EgressBandwidthAnnotation, an[common.ProvenanceDefaultedAnnotation])
+	assert.Equal(common.IngressBandwidthAnnotation, an[common.ProvenanceValidatedAnnotation])
+
+	// without a policy the forged provenance is dropped
+	a = newAnnotator(newNamespace("test-a", nil))
+	an, _, err = a.ConfigAnnotation(ctx, map[string]string{common.ProvenancePoliciesAnnotation: "forged"}, nil, "test-a")
+	assert.Nil(err)
+	assert.NotContains(an, common.ProvenancePoliciesAnnotation)
+
+	clr.Spec.EnforcementMode = webhook.EnforcementModeDryRun
+	a = newAnnotator(newNamespace("test-a", nil), clr)
+	an, _, err = a.ConfigAnnotation(ctx, map[string]string{}, nil, "test-a")
+	assert.Nil(err)
+	assert.Empty(an)
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injector
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/policy"
+)
+
+// ConfigNetworks evaluates the bandwidth of the Multus networks of a pod, see policy.EvaluateNetworks.
+func (a *PodAnnotator) ConfigNetworks(ctx context.Context, an map[string]string, namespace string) (map[string]string, admission.Warnings, error) {
+	if _, ok := an[common.MultusNetworksAnnotation]; !ok {
+		return an, nil, nil
+	}
+	policies, objects, err := PoliciesFor(ctx, a.Client, namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res := policy.EvaluateNetworks(policies, namespace, an)
+	var warnings admission.Warnings
+	switch res.Decision {
+	case policy.Deny:
+		return nil, nil, &annotationError{reason: res.Cause, errs: res.Errors}
+	case policy.Warn:
+		warnings = res.Reasons
+	}
+	if res.Mode == policy.ModeAudit || res.Mode == policy.ModeDryRun {
+		audit(a.recorder(ctx), res, objects)
+	}
+	return res.Annotations, warnings, nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injector
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+)
+
+func newNetworkLimitRange(mode webhook.EnforcementMode) *webhook.CustomLimitRange {
+	return &webhook.CustomLimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "test-a"},
+		Spec: webhook.CustomLimitRangeSpec{
+			LRange:          newLimitRange("1G", "100M", "500M"),
+			EnforcementMode: mode,
+			Networks: []webhook.NetworkLimitRange{
+				{
+					Name: "storage",
+					BandwidthRange: webhook.BandwidthRange{
+						Max:     webhook.CustomItems{Ingress: resource.MustParse("10G"), Egress: resource.MustParse("10G")},
+						Default: webhook.CustomItems{Ingress: resource.MustParse("5G")},
+					},
+				},
+				{
+					Name: "infra/data-plane",
+					BandwidthRange: webhook.BandwidthRange{
+						Max:     webhook.CustomItems{Egress: resource.MustParse("2G"), EgressBurst: resource.MustParse("100M")},
+						Default: webhook.CustomItems{Egress: resource.MustParse("1G")},
+					},
+				},
+			},
+		},
+	}
+}
+
+func decodeNetworks(value string) []map[string]interface{} {
+	var elements []map[string]interface{}
+	_ = json.Unmarshal([]byte(value), &elements)
+	return elements
+}
+
+func TestConfigNetworks(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+	a := newAnnotator(newNamespace("test-a", nil), newNetworkLimitRange(webhook.EnforcementModeEnforce))
+
+	// the defaults are written into the network selection, with a burst for every rate
+	an, warnings, err := a.ConfigNetworks(ctx, map[string]string{
+		common.MultusNetworksAnnotation: `[{"name":"storage","ips":["10.0.0.1"]},{"name":"data-plane","namespace":"infra","interface":"net2"},{"name":"other"}]`,
+	}, "test-a")
+	assert.NoError(err)
+	assert.Empty(warnings)
+	elements := decodeNetworks(an[common.MultusNetworksAnnotation])
+	assert.Len(elements, 3)
+	assert.Equal([]interface{}{"10.0.0.1"}, elements[0]["ips"])
+	assert.Equal(map[string]interface{}{"ingressRate": 5e9, "ingressBurst": float64(1<<32 - 1), "egressRate": 0.0, "egressBurst": 0.0}, elements[0]["bandwidth"])
+	assert.Equal(map[string]interface{}{"ingressRate": 0.0, "ingressBurst": 0.0, "egressRate": 1e9, "egressBurst": 1e8}, elements[1]["bandwidth"])
+	assert.NotContains(elements[2], "bandwidth")
+
+	// the comma separated form is rewritten as JSON when a default applies
+	an, _, err = a.ConfigNetworks(ctx, map[string]string{common.MultusNetworksAnnotation: "storage@net1"}, "test-a")
+	assert.NoError(err)
+	elements = decodeNetworks(an[common.MultusNetworksAnnotation])
+	assert.Equal("net1", elements[0]["interface"])
+	assert.Contains(elements[0], "bandwidth")
+
+	// values within the range are left unchanged
+	in := map[string]string{common.MultusNetworksAnnotation: "other"}
+	an, _, err = a.ConfigNetworks(ctx, in, "test-a")
+	assert.NoError(err)
+	assert.Equal(in, an)
+
+	// values out of the range are denied
+	_, _, err = a.ConfigNetworks(ctx, map[string]string{
+		common.MultusNetworksAnnotation: `[{"name":"storage","bandwidth":{"ingressRate":20000000000,"ingressBurst":1000000000}}]`,
+	}, "test-a")
+	assert.ErrorIs(err, common.ErrInvalidNetworkBandwidth)
+	assert.Contains(err.Error(), "metadata.annotations[k8s.v1.cni.cncf.io/networks][0].bandwidth.ingressRate")
+	assert.Contains(err.Error(), "must be at most 10G, the max set by CustomLimitRange test-a/a")
+
+	// an annotation Multus cannot parse either cannot be validated, which enforce mode denies
+	_, _, err = a.ConfigNetworks(ctx, map[string]string{common.MultusNetworksAnnotation: `[{"name":`}, "test-a")
+	assert.ErrorIs(err, common.ErrInvalidNetworkBandwidth)
+	assert.Contains(err.Error(), "metadata.annotations[k8s.v1.cni.cncf.io/networks]")
+}
+
+func TestConfigNetworksClamp(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+	a := newAnnotator(newNamespace("test-a", nil), newNetworkLimitRange(webhook.EnforcementModeClamp))
+	an, warnings, err := a.ConfigNetworks(ctx, map[string]string{
+		common.MultusNetworksAnnotation: `[{"name":"data-plane","namespace":"infra","bandwidth":{"egressRate":5000000000,"egressBurst":100000000}}]`,
+	}, "test-a")
+	assert.NoError(err)
+	assert.Equal(admission.Warnings{"CustomLimitRange test-a/a (clamp): metadata.annotations[k8s.v1.cni.cncf.io/networks][0].bandwidth.egressRate clamped from 5G to 2G"}, warnings)
+	elements := decodeNetworks(an[common.MultusNetworksAnnotation])
+	assert.Equal(2e9, elements[0]["bandwidth"].(map[string]interface{})["egressRate"])
+
+	// an annotation Multus cannot parse either is only reported in the other modes
+	in := map[string]string{common.MultusNetworksAnnotation: `[{"name":`}
+	an, warnings, err = a.ConfigNetworks(ctx, in, "test-a")
+	assert.NoError(err)
+	assert.Equal(in, an)
+	assert.Len(warnings, 1)
+
+	// dryRun leaves the pod unchanged
+	a = newAnnotator(newNamespace("test-a", nil), newNetworkLimitRange(webhook.EnforcementModeDryRun))
+	in = map[string]string{common.MultusNetworksAnnotation: "storage"}
+	an, warnings, err = a.ConfigNetworks(ctx, in, "test-a")
+	assert.NoError(err)
+	assert.Equal(in, an)
+	assert.Equal(admission.Warnings{"CustomLimitRange test-a/a (dryRun): would set metadata.annotations[k8s.v1.cni.cncf.io/networks][0].bandwidth.ingressRate=5G"}, warnings)
+}
+
+func TestDefaultNetworks(t *testing.T) {
+	assert := assert.New(t)
+
+	a := newAnnotator(newNamespace("test-a", nil), newNetworkLimitRange(webhook.EnforcementModeEnforce))
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "test-a",
+		Annotations: map[string]string{common.MultusNetworksAnnotation: "storage"}}}
+	_, err := a.Default(context.Background(), pod)
+	assert.NoError(err)
+	assert.Equal("500M", pod.Annotations[common.IngressBandwidthAnnotation])
+	assert.Contains(pod.Annotations[common.MultusNetworksAnnotation], `"ingressRate":5000000000`)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+)
+
+// policyObjects are the objects PodAnnotator reads on every admission.
+var policyObjects = []client.Object{
+	&webhook.CustomLimitRange{},
+	&webhook.ClusterCustomLimitRange{},
+	&webhook.BandwidthQuota{},
+	&corev1.Namespace{},
+	&corev1.Pod{},
+}
+
+// PolicyCacheOptions returns the options of the manager cache, which keeps only the metadata and phase of pods.
+func PolicyCacheOptions() cache.Options {
+	return cache.Options{ByObject: map[client.Object]cache.ByObject{
+		&corev1.Pod{}: {Transform: stripPod},
+	}}
+}
+
+// stripPod keeps the metadata, without the managed fields, and the phase of a pod.
+func stripPod(obj any) (any, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return obj, nil
+	}
+	stripped := &corev1.Pod{
+		TypeMeta:   pod.TypeMeta,
+		ObjectMeta: pod.ObjectMeta,
+		Status:     corev1.PodStatus{Phase: pod.Status.Phase},
+	}
+	stripped.ManagedFields = nil
+	return stripped, nil
+}
+
+// SetupPolicyCache registers the informers PodAnnotator reads and returns a readyz checker for their sync.
+func SetupPolicyCache(ctx context.Context, c cache.Cache) (healthz.Checker, error) {
+	informers := make(map[string]cache.Informer, len(policyObjects))
+	for _, obj := range policyObjects {
+		informer, err := c.GetInformer(ctx, obj)
+		if err != nil {
+			return nil, err
+		}
+		informers[fmt.Sprintf("%T", obj)] = informer
+	}
+
+	return func(_ *http.Request) error {
+		for name, informer := range informers {
+			if !informer.HasSynced() {
+				return fmt.Errorf("informer for %s has not synced", name)
+			}
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injector
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+)
+
+func TestSetupPolicyCache(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	c := &informertest.FakeInformers{Scheme: newScheme()}
+	check, err := SetupPolicyCache(ctx, c)
+	assert.Nil(err)
+	assert.NotNil(check(nil))
+
+	for _, obj := range policyObjects {
+		informer, err := c.FakeInformerFor(ctx, obj)
+		assert.Nil(err)
+		informer.Synced = true
+	}
+	assert.Nil(check(nil))
+}
+
+func TestConfigAnnotationContext(t *testing.T) {
+	assert := assert.New(t)
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	// the informers never sync: the read waits for them until the request is canceled
+	c, err := newInformerCache(ctx, true)
+	assert.Nil(err)
+	a := &PodAnnotator{Client: c}
+	req, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, _, err = a.ConfigAnnotation(req, map[string]string{}, nil, "test-a")
+	assert.ErrorIs(err, common.ErrMissingConfiguration)
+	assert.ErrorContains(err, "failed waiting for *webhook.CustomLimitRange Informer to sync")
+}
+
+func TestPolicyCacheStripsPods(t *testing.T) {
+	assert := assert.New(t)
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:          "web",
+			Namespace:     "test-a",
+			Annotations:   map[string]string{common.IngressBandwidthAnnotation: "500M"},
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+		},
+		Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: "nginx"}}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.1"},
+	}
+	c, err := newInformerCache(ctx, false, pod)
+	assert.Nil(err)
+
+	// the pods keep what the webhook and the controllers read: their metadata and phase
+	got := &corev1.Pod{}
+	assert.Nil(c.Get(ctx, client.ObjectKeyFromObject(pod), got))
+	assert.Equal(pod.Annotations, got.Annotations)
+	assert.Equal(corev1.PodRunning, got.Status.Phase)
+	assert.Empty(got.Spec.Containers)
+	assert.Empty(got.Status.PodIP)
+	assert.Empty(got.ManagedFields)
+}
+
+// newInformerCache returns the controller-runtime informer cache the manager serves admissions
+// from, with the informers of SetupPolicyCache, started. The informers list the given objects
+// instead of calling an API server, or never return when block is set, as when it is unreachable.
+func newInformerCache(ctx context.Context, block bool, objs ...client.Object) (cache.Cache, error) {
+	scheme := newScheme()
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for _, obj := range policyObjects {
+		gvk, err := apiutil.GVKForObject(obj, scheme)
+		if err != nil {
+			return nil, err
+		}
+		scope := meta.RESTScopeNamespace
+		if gvk.Kind == "Namespace" || gvk.Kind == "ClusterCustomLimitRange" {
+			scope = meta.RESTScopeRoot
+		}
+		mapper.Add(gvk, scope)
+	}
+	byKind := map[schema.GroupVersionKind][]runtime.Object{}
+	for _, obj := range objs {
+		gvk, err := apiutil.GVKForObject(obj, scheme)
+		if err != nil {
+			return nil, err
+		}
+		byKind[gvk] = append(byKind[gvk], obj)
+	}
+
+	opts := PolicyCacheOptions()
+	opts.Scheme = scheme
+	opts.Mapper = mapper
+	opts.NewInformer = func(_ toolscache.ListerWatcher, obj runtime.Object, resync time.Duration, indexers toolscache.Indexers) toolscache.SharedIndexInformer {
+		gvk, _ := apiutil.GVKForObject(obj, scheme)
+		lw := fixtureListWatch{&toolscache.ListWatch{
+			ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+				if block {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				}
+				list, err := scheme.New(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+				if err != nil {
+					return nil, err
+				}
+				return list, meta.SetList(list, byKind[gvk])
+			},
+			WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+				return watch.NewFake(), nil
+			},
+		}}
+		return toolscache.NewSharedIndexInformer(lw, obj, resync, indexers)
+	}
+	c, err := cache.New(&rest.Config{Host: "http://127.0.0.1:0"}, opts)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := SetupPolicyCache(ctx, c); err != nil {
+		return nil, err
+	}
+	go func() { _ = c.Start(ctx) }()
+
+	wait := ctx
+	if block {
+		var cancel context.CancelFunc
+		wait, cancel = context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+	}
+	if !c.WaitForCacheSync(wait) && !block {
+		return nil, fmt.Errorf("informer cache did not sync")
+	}
+	return c, nil
+}
+
+// fixtureListWatch lists the fixtures, its watch sends no event: the reflector must list, not
+// stream the initial objects from the watch.
+type fixtureListWatch struct {
+	*toolscache.ListWatch
+}
+
+func (fixtureListWatch) IsWatchListSemanticsUnSupported() bool {
+	return true
+}
+
+// benchmarkObjects returns one CustomLimitRange, one BandwidthQuota and ten pods in each of n namespaces.
+func benchmarkObjects(n int) []client.Object {
+	objs := make([]client.Object, 0, n*13)
+	for i := 0; i < n; i++ {
+		ns := fmt.Sprintf("ns-%d", i)
+		objs = append(objs, newNamespace(ns, nil),
+			&webhook.CustomLimitRange{
+				ObjectMeta: metav1.ObjectMeta{Name: "clr", Namespace: ns},
+				Spec:       webhook.CustomLimitRangeSpec{LRange: newLimitRange("1G", "100M", "500M")},
+			},
+			&webhook.BandwidthQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: ns},
+				Spec:       webhook.BandwidthQuotaSpec{Hard: webhook.CustomItems{Ingress: resource.MustParse("1P")}},
+			})
+		for j := 0; j < 10; j++ {
+			objs = append(objs, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:        fmt.Sprintf("pod-%d", j),
+				Namespace:   ns,
+				Annotations: map[string]string{common.IngressBandwidthAnnotation: "500M"},
+			}})
+		}
+	}
+	return objs
+}
+
+// BenchmarkDefault measures the admission of a pod in one of thousands of namespaces, served from
+// the informer cache of the manager and, for comparison, from the fake client, which scans every
+// object of a kind like an unindexed List.
+func BenchmarkDefault(b *testing.B) {
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	for _, n := range []int{100, 1000, 5000} {
+		objs := benchmarkObjects(n)
+		informers, err := newInformerCache(ctx, false, objs...)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, reader := range []struct {
+			name   string
+			client client.Reader
+		}{
+			{"cache", informers},
+			{"fake-client", fake.NewClientBuilder().WithScheme(newScheme()).WithObjects(objs...).Build()},
+		} {
+			b.Run(fmt.Sprintf("%s/namespaces=%d", reader.name, n), func(b *testing.B) {
+				a := &PodAnnotator{Client: reader.client}
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: fmt.Sprintf("ns-%d", i%n)}}
+					if _, err := a.Default(ctx, pod); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/policy"
+)
+
+// WorkloadAnnotator validates the bandwidth annotations of the pod templates of workloads.
+type WorkloadAnnotator struct {
+	// Client reads the policies. In the manager it is the informer cache, see SetupPolicyCache.
+	Client   client.Reader
+	Decoder  admission.Decoder
+	Recorder record.EventRecorder
+	// InjectDefaults injects the defaults of the policies into the pod templates.
+	InjectDefaults bool
+}
+
+var _ admission.Handler = &WorkloadAnnotator{}
+
+// newWorkload returns an empty object of a workload kind, or nil for the other kinds.
+func newWorkload(kind schema.GroupKind) runtime.Object {
+	switch kind {
+	case schema.GroupKind{Group: appsv1.GroupName, Kind: "Deployment"}:
+		return &appsv1.Deployment{}
+	case schema.GroupKind{Group: appsv1.GroupName, Kind: "StatefulSet"}:
+		return &appsv1.StatefulSet{}
+	case schema.GroupKind{Group: appsv1.GroupName, Kind: "DaemonSet"}:
+		return &appsv1.DaemonSet{}
+	case schema.GroupKind{Group: appsv1.GroupName, Kind: "ReplicaSet"}:
+		return &appsv1.ReplicaSet{}
+	case schema.GroupKind{Group: batchv1.GroupName, Kind: "Job"}:
+		return &batchv1.Job{}
+	case schema.GroupKind{Group: batchv1.GroupName, Kind: "CronJob"}:
+		return &batchv1.CronJob{}
+	}
+	return nil
+}
+
+// podTemplate returns the pod template of a workload and its field path.
+func podTemplate(obj runtime.Object) (*corev1.PodTemplateSpec, *field.Path) {
+	template := field.NewPath("spec", "template")
+	switch w := obj.(type) {
+	case *appsv1.Deployment:
+		return &w.Spec.Template, template
+	case *appsv1.StatefulSet:
+		return &w.Spec.Template, template
+	case *appsv1.DaemonSet:
+		return &w.Spec.Template, template
+	case *appsv1.ReplicaSet:
+		return &w.Spec.Template, template
+	case *batchv1.Job:
+		return &w.Spec.Template, template
+	case *batchv1.CronJob:
+		return &w.Spec.JobTemplate.Spec.Template, field.NewPath("spec", "jobTemplate", "spec", "template")
+	}
+	return nil, nil
+}
+
+// Handle implements admission.Handler for workloads.
+func (a *WorkloadAnnotator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+	kind := schema.GroupKind{Group: req.Kind.Group, Kind: req.Kind.Kind}
+	obj := newWorkload(kind)
+	if obj == nil {
+		return admission.Allowed("")
+	}
+	if err := a.Decoder.Decode(req, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	template, path := podTemplate(obj)
+
+	// an update that leaves the pod template metadata unchanged creates no new pods
+	if req.Operation == admissionv1.Update && len(req.OldObject.Raw) > 0 {
+		old := newWorkload(kind)
+		if err := a.Decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		oldTemplate, _ := podTemplate(old)
+		if maps.Equal(oldTemplate.Annotations, template.Annotations) && maps.Equal(oldTemplate.Labels, template.Labels) {
+			return admission.Allowed("")
+		}
+	}
+
+	if policy.Disabled(template.Annotations) {
+		return admission.Allowed("")
+	}
+	policies, objects, err := PoliciesFor(ctx, a.Client, req.Namespace)
+	if err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	// the bandwidth of the pods, then of the networks they attach to, with the defaults of the pods
+	res := policy.EvaluateTemplate(policies, template)
+	results := []policy.Result{res}
+	if res.Decision != policy.Deny {
+		res = policy.EvaluateNetworks(policies, req.Namespace, res.Annotations)
+		results = append(results, res)
+	}
+	customlimitrangelog.Info("WorkloadAnnotator", "kind", kind, "namespace", req.Namespace, "name", req.Name, "decision", res.Decision)
+
+	var warnings admission.Warnings
+	for _, r := range results {
+		if r.Decision == policy.Deny {
+			ae := &annotationError{reason: r.Cause, errs: templateErrors(path, r.Errors)}
+			status := ae.status(kind, req.Name).ErrStatus
+			return admission.Response{AdmissionResponse: admissionv1.AdmissionResponse{Allowed: false, Result: &status}}
+		}
+		if r.Mode == policy.ModeAudit || r.Mode == policy.ModeDryRun {
+			recorder := a.Recorder
+			if req.DryRun != nil && *req.DryRun {
+				recorder = nil
+			}
+			audit(recorder, r, objects)
+		}
+		for _, reason := range r.Reasons {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", path.Child("metadata"), reason))
+		}
+	}
+	if !a.InjectDefaults || maps.Equal(res.Annotations, template.Annotations) {
+		return admission.Allowed("").WithWarnings(warnings...)
+	}
+
+	template.Annotations = res.Annotations
+	marshalled, err := json.Marshal(obj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshalled).WithWarnings(warnings...)
+}
+
+// templateErrors moves the errors on the pod annotations under the path of the pod template.
+func templateErrors(path *field.Path, errs field.ErrorList) field.ErrorList {
+	moved := make(field.ErrorList, 0, len(errs))
+	for _, err := range errs {
+		e := *err
+		e.Field = path.String() + "." + err.Field
+		moved = append(moved, &e)
+	}
+	return moved
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+)
+
+func newWorkloadAnnotator(objs ...client.Object) *WorkloadAnnotator {
+	return &WorkloadAnnotator{
+		Client:  fake.NewClientBuilder().WithScheme(newScheme()).WithObjects(objs...).Build(),
+		Decoder: admission.NewDecoder(newScheme()),
+	}
+}
+
+func newLimitRangeWithMode(mode webhook.EnforcementMode) *webhook.CustomLimitRange {
+	return &webhook.CustomLimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "test-a"},
+		Spec:       webhook.CustomLimitRangeSpec{LRange: newLimitRange("1G", "100M", "500M"), EnforcementMode: mode},
+	}
+}
+
+func newDeployment(annotations map[string]string) *appsv1.Deployment {
+	d := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-a"}}
+	d.Spec.Template.Labels = map[string]string{"app": "web"}
+	d.Spec.Template.Annotations = annotations
+	return d
+}
+
+func workloadRequest(op admissionv1.Operation, kind metav1.GroupVersionKind, obj, old runtime.Object) admission.Request {
+	req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: op,
+		Kind:      kind,
+		Namespace: "test-a",
+		Name:      "web",
+	}}
+	req.Object.Raw, _ = json.Marshal(obj)
+	if old != nil {
+		req.OldObject.Raw, _ = json.Marshal(old)
+	}
+	return req
+}
+
+var deploymentKind = metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+
+func TestWorkloadAnnotatorEnforce(t *testing.T) {
+	assert := assert.New(t)
+
+	a := newWorkloadAnnotator(newNamespace("test-a", nil), newLimitRangeWithMode(webhook.EnforcementModeEnforce))
+	big := newDeployment(map[string]string{common.IngressBandwidthAnnotation: "10G"})
+
+	resp := a.Handle(context.Background(), workloadRequest(admissionv1.Create, deploymentKind, big, nil))
+	assert.False(resp.Allowed)
+	assert.Equal(int32(http.StatusUnprocessableEntity), resp.Result.Code)
+	assert.Equal("Deployment", resp.Result.Details.Kind)
+	assert.Equal("apps", resp.Result.Details.Group)
+	assert.Len(resp.Result.Details.Causes, 1)
+	assert.Equal("spec.template.metadata.annotations[kubernetes.io/ingress-bandwidth]", resp.Result.Details.Causes[0].Field)
+	assert.Contains(resp.Result.Details.Causes[0].Message, "CustomLimitRange test-a/a")
+
+	// the template is valid: admitted unchanged, the PodAnnotator injects the defaults into the pods
+	resp = a.Handle(context.Background(), workloadRequest(admissionv1.Create, deploymentKind, newDeployment(nil), nil))
+	assert.True(resp.Allowed)
+	assert.Empty(resp.Patches)
+
+	// an update leaving the template metadata unchanged is not evaluated
+	scaled := big.DeepCopy()
+	scaled.Spec.Replicas = new(int32)
+	resp = a.Handle(context.Background(), workloadRequest(admissionv1.Update, deploymentKind, scaled, big))
+	assert.True(resp.Allowed)
+
+	fixed := newDeployment(map[string]string{common.IngressBandwidthAnnotation: "1G"})
+	resp = a.Handle(context.Background(), workloadRequest(admissionv1.Update, deploymentKind, fixed, big))
+	assert.True(resp.Allowed)
+
+	resp = a.Handle(context.Background(), workloadRequest(admissionv1.Update, deploymentKind, big, fixed))
+	assert.False(resp.Allowed)
+
+	disabled := newDeployment(map[string]string{common.IngressBandwidthAnnotation: "10G", common.WebhookPodDisable: "disable"})
+	resp = a.Handle(context.Background(), workloadRequest(admissionv1.Create, deploymentKind, disabled, nil))
+	assert.True(resp.Allowed)
+}
+
+func TestWorkloadAnnotatorWarn(t *testing.T) {
+	assert := assert.New(t)
+
+	a := newWorkloadAnnotator(newNamespace("test-a", nil), newLimitRangeWithMode(webhook.EnforcementModeWarn))
+	big := newDeployment(map[string]string{common.IngressBandwidthAnnotation: "10G"})
+
+	resp := a.Handle(context.Background(), workloadRequest(admissionv1.Create, deploymentKind, big, nil))
+	assert.True(resp.Allowed)
+	assert.Empty(resp.Patches)
+	assert.Len(resp.Warnings, 1)
+	assert.Contains(resp.Warnings[0], "spec.template.metadata: CustomLimitRange test-a/a (warn)")
+}
+
+func TestWorkloadAnnotatorNetworks(t *testing.T) {
+	assert := assert.New(t)
+
+	a := newWorkloadAnnotator(newNamespace("test-a", nil), newNetworkLimitRange(webhook.EnforcementModeEnforce))
+	big := newDeployment(map[string]string{
+		common.MultusNetworksAnnotation: `[{"name":"storage","bandwidth":{"ingressRate":20000000000,"ingressBurst":1000000000}}]`,
+	})
+	resp := a.Handle(context.Background(), workloadRequest(admissionv1.Create, deploymentKind, big, nil))
+	assert.False(resp.Allowed)
+	assert.Len(resp.Result.Details.Causes, 1)
+	assert.Equal("spec.template.metadata.annotations[k8s.v1.cni.cncf.io/networks][0].bandwidth.ingressRate", resp.Result.Details.Causes[0].Field)
+
+	// the defaults of the networks are injected along with those of the pods
+	a.InjectDefaults = true
+	resp = a.Handle(context.Background(), workloadRequest(admissionv1.Create, deploymentKind, newDeployment(map[string]string{
+		common.MultusNetworksAnnotation: "storage",
+	}), nil))
+	assert.True(resp.Allowed)
+	patched := map[string]interface{}{}
+	for _, p := range resp.Patches {
+		patched[p.Path] = p.Value
+	}
+	assert.Equal("500M", patched["/spec/template/metadata/annotations/kubernetes.io~1ingress-bandwidth"])
+	assert.Contains(patched["/spec/template/metadata/annotations/k8s.v1.cni.cncf.io~1networks"], `"ingressRate":5000000000`)
+}
+
+func TestWorkloadAnnotatorInjectDefaults(t *testing.T) {
+	assert := assert.New(t)
+
+	a := newWorkloadAnnotator(newNamespace("test-a", nil), newLimitRangeWithMode(webhook.EnforcementModeEnforce))
+	a.InjectDefaults = true
+
+	job := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test-a"}}
+	kind := metav1.GroupVersionKind{Group: "batch", Version: "v1", Kind: "CronJob"}
+	resp := a.Handle(context.Background(), workloadRequest(admissionv1.Create, kind, job, nil))
+	assert.True(resp.Allowed)
+	assert.Len(resp.Patches, 1)
+	assert.Equal("/spec/jobTemplate/spec/template/metadata/annotations", resp.Patches[0].Path)
+	assert.Equal(map[string]interface{}{
+		common.IngressBandwidthAnnotation: "500M",
+		common.EgressBandwidthAnnotation:  "500M",
+	}, resp.Patches[0].Value)
+
+	job.Spec.JobTemplate.Spec.Template.Annotations = map[string]string{common.EgressBandwidthAnnotation: "10G"}
+	resp = a.Handle(context.Background(), workloadRequest(admissionv1.Create, kind, job, nil))
+	assert.False(resp.Allowed)
+	assert.Equal("spec.jobTemplate.spec.template.metadata.annotations[kubernetes.io/egress-bandwidth]", resp.Result.Details.Causes[0].Field)
+}
+
+func TestWorkloadAnnotatorIgnored(t *testing.T) {
+	assert := assert.New(t)
+
+	a := newWorkloadAnnotator(newNamespace("test-a", nil), newLimitRangeWithMode(webhook.EnforcementModeEnforce))
+	big := newDeployment(map[string]string{common.IngressBandwidthAnnotation: "10G"})
+
+	resp := a.Handle(context.Background(), workloadRequest(admissionv1.Delete, deploymentKind, big, nil))
+	assert.True(resp.Allowed)
+
+	kind := metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "ControllerRevision"}
+	resp = a.Handle(context.Background(), workloadRequest(admissionv1.Create, kind, big, nil))
+	assert.True(resp.Allowed)
+
+	// no policy in the namespace
+	resp = newWorkloadAnnotator(newNamespace("test-a", nil)).Handle(context.Background(), workloadRequest(admissionv1.Create, deploymentKind, big, nil))
+	assert.True(resp.Allowed)
+}
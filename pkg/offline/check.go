@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package offline
+
+import (
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/policy"
+)
+
+// Result is the evaluation of the pods of one object.
+type Result struct {
+	Source    string          `json:"source"`
+	Kind      string          `json:"kind"`
+	Namespace string          `json:"namespace"`
+	Name      string          `json:"name"`
+	Decision  policy.Decision `json:"decision"`
+	// Policy and Mode are those of the policies in effect, if any.
+	Policy    string      `json:"policy,omitempty"`
+	Mode      policy.Mode `json:"mode,omitempty"`
+	Reasons   []string    `json:"reasons,omitempty"`
+	Defaulted []string    `json:"defaulted,omitempty"`
+}
+
+// Report is the evaluation of the objects carrying pods in the manifests.
+type Report struct {
+	Results []Result `json:"results"`
+	Passed  int      `json:"passed"`
+	Warned  int      `json:"warned"`
+	Failed  int      `json:"failed"`
+}
+
+// Failing reports whether an object is denied or, when strict, admitted with warnings.
+func (r *Report) Failing(strict bool) bool {
+	return r.Failed > 0 || (strict && r.Warned > 0)
+}
+
+// Check evaluates the Pods and the pod templates of the workloads against the policies in effect
+// in their namespace, as the PodAnnotator webhook would admit their pods. The pod annotations of
+// the admitted objects are replaced by the mutated ones, see WriteTo. Objects without a namespace
+// are evaluated in namespace. The invalid policies come first, as failed results.
+func (m *Manifests) Check(namespace string) Report {
+	report := Report{Results: append([]Result{}, m.Invalid...), Failed: len(m.Invalid)}
+	for _, doc := range m.Documents {
+		if doc.Object == nil {
+			continue
+		}
+		path, ok := templatePaths[doc.Object.GroupVersionKind().GroupKind()]
+		if !ok {
+			continue
+		}
+		res := m.check(doc, path, namespace)
+		switch res.Decision {
+		case policy.Deny:
+			report.Failed++
+		case policy.Warn:
+			report.Warned++
+		default:
+			report.Passed++
+		}
+		report.Results = append(report.Results, res)
+	}
+	return report
+}
+
+func (m *Manifests) check(doc *Document, path []string, namespace string) Result {
+	obj := doc.Object
+	ns := obj.GetNamespace()
+	if ns == "" {
+		ns = namespace
+	}
+	name := obj.GetName()
+	if name == "" {
+		name = obj.GetGenerateName()
+	}
+	result := Result{Source: doc.Source, Kind: obj.GetKind(), Namespace: ns, Name: name}
+
+	podLabels, _, err := unstructured.NestedStringMap(obj.Object, append(path, "labels")...)
+	if err == nil {
+		var annotations map[string]string
+		annotations, _, err = unstructured.NestedStringMap(obj.Object, append(path, "annotations")...)
+		if err == nil {
+			return m.evaluate(doc, path, result, podLabels, annotations)
+		}
+	}
+	result.Decision = policy.Deny
+	result.Reasons = []string{err.Error()}
+	return result
+}
+
+func (m *Manifests) evaluate(doc *Document, path []string, result Result, podLabels, annotations map[string]string) Result {
+	policies := policy.Applicable(m.Policies, result.Namespace, m.namespaceLabels(result.Namespace))
+	res := policy.Evaluate(policies, podLabels, annotations)
+	result.Decision, result.Policy, result.Mode = res.Decision, res.Source, res.Mode
+	result.Reasons, result.Defaulted = res.Reasons, res.Defaulted
+	if res.Decision == policy.Deny {
+		return result
+	}
+
+	// the networks the pods attach to, with the defaults of the pods
+	res = policy.EvaluateNetworks(policies, result.Namespace, res.Annotations)
+	if res.Decision != policy.Admit {
+		result.Decision = res.Decision
+	}
+	if result.Policy == "" {
+		result.Policy, result.Mode = res.Source, res.Mode
+	}
+	result.Reasons = append(result.Reasons, res.Reasons...)
+	result.Defaulted = append(result.Defaulted, res.Defaulted...)
+	if res.Decision == policy.Deny || maps.Equal(res.Annotations, annotations) {
+		return result
+	}
+	if err := unstructured.SetNestedStringMap(doc.Object.Object, res.Annotations, append(path, "annotations")...); err != nil {
+		result.Decision = policy.Deny
+		result.Reasons = append(result.Reasons, err.Error())
+		return result
+	}
+	doc.changed = true
+	return result
+}
+
+// namespaceLabels returns the labels of a namespace defined in the manifests, with the
+// kubernetes.io/metadata.name label the API server sets on every namespace.
+func (m *Manifests) namespaceLabels(namespace string) map[string]string {
+	nsLabels := map[string]string{corev1.LabelMetadataName: namespace}
+	for k, v := range m.Namespaces[namespace] {
+		nsLabels[k] = v
+	}
+	return nsLabels
+}
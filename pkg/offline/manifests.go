@@ -0,0 +1,208 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package offline checks Kubernetes manifests against the bandwidth policies found in the same
+// manifests, without a cluster: Pods and the pod templates of workloads are evaluated with the
+// rules of the PodAnnotator webhook, see policy.Evaluate, so that CI can catch bandwidth
+// annotations out of range before they reach the cluster.
+package offline
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/policy"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+	v2 "github.com/kubeservice-stack/custom-limit-range/pkg/webhook/v2"
+)
+
+// templatePaths maps the kinds carrying pods to the path of the pod metadata in the object.
+var templatePaths = map[schema.GroupKind][]string{
+	{Kind: "Pod"}:                        {"metadata"},
+	{Kind: "ReplicationController"}:      {"spec", "template", "metadata"},
+	{Group: "apps", Kind: "Deployment"}:  {"spec", "template", "metadata"},
+	{Group: "apps", Kind: "StatefulSet"}: {"spec", "template", "metadata"},
+	{Group: "apps", Kind: "DaemonSet"}:   {"spec", "template", "metadata"},
+	{Group: "apps", Kind: "ReplicaSet"}:  {"spec", "template", "metadata"},
+	{Group: "batch", Kind: "Job"}:        {"spec", "template", "metadata"},
+	{Group: "batch", Kind: "CronJob"}:    {"spec", "jobTemplate", "spec", "template", "metadata"},
+}
+
+// Document is one document of the manifests.
+type Document struct {
+	// Source is the file and the index of the document in the file, e.g. "deploy.yaml#2".
+	Source string
+	// Object is nil for the documents that are not Kubernetes objects, e.g. comments only.
+	Object *unstructured.Unstructured
+	raw    []byte
+	// changed is set when the pod annotations of the object were mutated.
+	changed bool
+}
+
+// Manifests are the documents read from the input, with the policies and the namespaces they define.
+type Manifests struct {
+	Documents []*Document
+	Policies  []policy.Policy
+	// Namespaces holds the labels of the namespaces, used to select the cluster policies.
+	Namespaces map[string]map[string]string
+	// Invalid are the policies the API server would reject, which are left out of Policies.
+	Invalid []Result
+}
+
+// Load reads the YAML or JSON documents of r, named after source in the report. The
+// CustomLimitRanges, v1 or v2, and the ClusterCustomLimitRanges become policies; they are
+// defaulted and validated as their webhooks would before they are stored. Documents without a
+// namespace are placed in namespace.
+func (m *Manifests) Load(source string, r io.Reader, namespace string) error {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	for i := 1; ; i++ {
+		raw, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", common.ErrInvalidManifest, source, err)
+		}
+		doc := &Document{Source: fmt.Sprintf("%s#%d", source, i), raw: bytes.TrimSpace(raw)}
+		if err := m.add(doc, namespace); err != nil {
+			return fmt.Errorf("%w: %s: %v", common.ErrInvalidManifest, doc.Source, err)
+		}
+	}
+}
+
+func (m *Manifests) add(doc *Document, namespace string) error {
+	if len(doc.raw) == 0 {
+		return nil
+	}
+	js, err := yaml.YAMLToJSON(doc.raw)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(js)) == 0 || string(js) == "null" {
+		m.Documents = append(m.Documents, doc)
+		return nil
+	}
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(js); err != nil {
+		return err
+	}
+	doc.Object = obj
+	m.Documents = append(m.Documents, doc)
+
+	gvk := obj.GroupVersionKind()
+	switch {
+	case gvk.GroupKind() == schema.GroupKind{Kind: "Namespace"}:
+		if m.Namespaces == nil {
+			m.Namespaces = map[string]map[string]string{}
+		}
+		m.Namespaces[obj.GetName()] = obj.GetLabels()
+	case gvk.Group == webhook.GroupVersion.Group:
+		return m.addPolicy(doc, namespace)
+	}
+	return nil
+}
+
+func (m *Manifests) addPolicy(doc *Document, namespace string) error {
+	obj := doc.Object
+	gvk := obj.GroupVersionKind()
+	ctx := context.Background()
+	switch gvk.Kind {
+	case "CustomLimitRange":
+		clr := &webhook.CustomLimitRange{}
+		if gvk.Version == v2.GroupVersion.Version {
+			hub := &v2.CustomLimitRange{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, hub); err != nil {
+				return err
+			}
+			if err := clr.ConvertFrom(hub); err != nil {
+				return err
+			}
+		} else if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, clr); err != nil {
+			return err
+		}
+		if clr.Namespace == "" {
+			clr.Namespace = namespace
+		}
+		if err := clr.Default(ctx, clr); err != nil {
+			return err
+		}
+		if _, err := (&webhook.CustomLimitRangeValidator{}).ValidateCreate(ctx, clr); err != nil {
+			m.invalid(doc, clr.Namespace, err)
+			return nil
+		}
+		m.Policies = append(m.Policies, clr.Policy())
+	case "ClusterCustomLimitRange":
+		cclr := &webhook.ClusterCustomLimitRange{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, cclr); err != nil {
+			return err
+		}
+		if _, err := cclr.ValidateCreate(ctx, cclr); err != nil {
+			m.invalid(doc, "", err)
+			return nil
+		}
+		m.Policies = append(m.Policies, cclr.Policy())
+	}
+	return nil
+}
+
+// invalid records a policy rejected by its validation, with a reason for every invalid field.
+func (m *Manifests) invalid(doc *Document, namespace string, err error) {
+	res := Result{Source: doc.Source, Kind: doc.Object.GetKind(), Namespace: namespace, Name: doc.Object.GetName(),
+		Decision: policy.Deny}
+	var status apierrors.APIStatus
+	if errors.As(err, &status) && status.Status().Details != nil && len(status.Status().Details.Causes) > 0 {
+		for _, cause := range status.Status().Details.Causes {
+			res.Reasons = append(res.Reasons, fmt.Sprintf("%s: %s", cause.Field, cause.Message))
+		}
+	} else {
+		res.Reasons = []string{err.Error()}
+	}
+	m.Invalid = append(m.Invalid, res)
+}
+
+// WriteTo writes the documents back as a YAML stream. The objects whose pod annotations were
+// mutated are re-encoded, the others are written as they were read.
+func (m *Manifests) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	for i, doc := range m.Documents {
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		raw := doc.raw
+		if doc.changed {
+			out, err := yaml.Marshal(doc.Object.Object)
+			if err != nil {
+				return 0, fmt.Errorf("%w: %s: %v", common.ErrInvalidManifest, doc.Source, err)
+			}
+			raw = out
+		}
+		buf.Write(bytes.TrimSpace(raw))
+		buf.WriteString("\n")
+	}
+	return buf.WriteTo(w)
+}
@@ -0,0 +1,389 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package offline
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/policy"
+)
+
+const policies = `
+apiVersion: custom.cmss.com/v1
+kind: CustomLimitRange
+metadata:
+  name: limits
+  namespace: test-a
+spec:
+  limitrange:
+    type: Pod
+    max:
+      ingress-bandwidth: 1G
+    min:
+      ingress-bandwidth: 100M
+    default:
+      ingress-bandwidth: 500M
+---
+apiVersion: custom.cmss.com/v2
+kind: CustomLimitRange
+metadata:
+  name: warn
+spec:
+  enforcementMode: warn
+  max:
+    egress:
+      rate: 1G
+---
+apiVersion: custom.cmss.com/v1
+kind: ClusterCustomLimitRange
+metadata:
+  name: teams
+spec:
+  namespaceSelector:
+    matchLabels:
+      team: b
+  limitrange:
+    type: Pod
+    default:
+      egress-bandwidth: 10M
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: test-b
+  labels:
+    team: b
+`
+
+const workloads = `
+# a comment only document
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: big
+  namespace: test-a
+  annotations:
+    kubernetes.io/ingress-bandwidth: 10G
+spec:
+  containers:
+  - name: c
+    image: nginx
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: test-a
+spec:
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: c
+        image: nginx
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        metadata:
+          annotations:
+            kubernetes.io/egress-bandwidth: 2G
+        spec:
+          containers:
+          - name: c
+            image: busybox
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: batch
+  namespace: test-b
+spec:
+  template:
+    spec:
+      containers:
+      - name: c
+        image: busybox
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: untouched
+data:
+  key: value
+`
+
+func load(t *testing.T) *Manifests {
+	m := &Manifests{}
+	assert.NoError(t, m.Load("policies.yaml", strings.NewReader(policies), "default"))
+	assert.NoError(t, m.Load("workloads.yaml", strings.NewReader(workloads), "default"))
+	return m
+}
+
+func TestCheck(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	m := load(t)
+	assert.Len(m.Policies, 3)
+	assert.Equal(map[string]string{"team": "b"}, m.Namespaces["test-b"])
+
+	report := m.Check("default")
+	assert.Equal(2, report.Passed)
+	assert.Equal(1, report.Warned)
+	assert.Equal(1, report.Failed)
+	assert.True(report.Failing(false))
+	assert.Len(report.Results, 4)
+
+	big := report.Results[0]
+	assert.Equal("workloads.yaml#2", big.Source)
+	assert.Equal("Pod", big.Kind)
+	assert.Equal(policy.Deny, big.Decision)
+	assert.Equal("CustomLimitRange test-a/limits", big.Policy)
+	assert.Contains(big.Reasons[0], "must be at most 1G")
+
+	web := report.Results[1]
+	assert.Equal(policy.Admit, web.Decision)
+	assert.Equal([]string{common.IngressBandwidthAnnotation}, web.Defaulted)
+
+	// the v2 policy without a namespace is placed in the default namespace, like the CronJob
+	backup := report.Results[2]
+	assert.Equal("default", backup.Namespace)
+	assert.Equal(policy.Warn, backup.Decision)
+	assert.Equal(policy.ModeWarn, backup.Mode)
+
+	// the ClusterCustomLimitRange selects test-b from the labels of the Namespace manifest
+	job := report.Results[3]
+	assert.Equal("test-b", job.Namespace)
+	assert.Equal("ClusterCustomLimitRange teams", job.Policy)
+	assert.Equal(policy.Admit, job.Decision)
+	assert.Equal([]string{common.EgressBandwidthAnnotation}, job.Defaulted)
+}
+
+func TestCheckSelectsNamespaceByName(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	m := &Manifests{}
+	assert.NoError(m.Load("in.yaml", strings.NewReader(`
+apiVersion: custom.cmss.com/v1
+kind: ClusterCustomLimitRange
+metadata:
+  name: prod
+spec:
+  namespaceSelector:
+    matchLabels:
+      kubernetes.io/metadata.name: prod
+  limitrange:
+    type: Pod
+    max:
+      ingress-bandwidth: 1G
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: big
+  annotations:
+    kubernetes.io/ingress-bandwidth: 2G
+`), "default"))
+
+	report := m.Check("default")
+	assert.False(report.Failing(true))
+	report = m.Check("prod")
+	assert.True(report.Failing(false))
+}
+
+func TestCheckNetworks(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	m := &Manifests{}
+	assert.NoError(m.Load("in.yaml", strings.NewReader(`
+apiVersion: custom.cmss.com/v1
+kind: CustomLimitRange
+metadata:
+  name: storage
+spec:
+  limitrange:
+    type: Pod
+    max:
+      ingress-bandwidth: 1G
+  networks:
+  - name: storage
+    max:
+      ingress-bandwidth: 10G
+    default:
+      ingress-bandwidth: 5G
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: big
+  annotations:
+    k8s.v1.cni.cncf.io/networks: '[{"name":"storage","bandwidth":{"ingressRate":20000000000,"ingressBurst":1000000000}}]'
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: defaulted
+  annotations:
+    k8s.v1.cni.cncf.io/networks: storage@net1
+`), "default"))
+
+	report := m.Check("default")
+	assert.Len(report.Results, 2)
+	assert.Equal(1, report.Failed)
+
+	// the network bandwidth out of range is denied, though the pod bandwidth is within range
+	big := report.Results[0]
+	assert.Equal(policy.Deny, big.Decision)
+	assert.Equal("CustomLimitRange default/storage", big.Policy)
+	assert.Len(big.Reasons, 1)
+	assert.Contains(big.Reasons[0], "metadata.annotations[k8s.v1.cni.cncf.io/networks][0].bandwidth.ingressRate")
+	assert.Contains(big.Reasons[0], "must be at most 10G")
+
+	defaulted := report.Results[1]
+	assert.Equal(policy.Admit, defaulted.Decision)
+	assert.Equal([]string{"metadata.annotations[k8s.v1.cni.cncf.io/networks][0].bandwidth.ingressRate"}, defaulted.Defaulted)
+}
+
+func TestWriteTo(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	m := load(t)
+	m.Check("default")
+	var out bytes.Buffer
+	_, err := m.WriteTo(&out)
+	assert.NoError(err)
+
+	docs := strings.Split(out.String(), "---\n")
+	assert.Len(docs, 10)
+	// denied and unchanged objects are written as they were read
+	assert.Contains(docs[5], "kubernetes.io/ingress-bandwidth: 10G")
+	assert.Contains(docs[9], "  key: value")
+	// admitted objects carry the injected defaults
+	assert.Contains(docs[6], "kubernetes.io/ingress-bandwidth: 500M")
+	assert.Contains(docs[8], "kubernetes.io/egress-bandwidth: 10M")
+}
+
+func TestReportWrite(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	report := load(t).Check("default")
+
+	var text bytes.Buffer
+	assert.NoError(report.Write(&text, FormatText))
+	assert.Contains(text.String(), "FAIL Pod test-a/big (workloads.yaml#2): CustomLimitRange test-a/limits (enforce)\n")
+	assert.Contains(text.String(), "    defaulted kubernetes.io/ingress-bandwidth\n")
+	assert.Contains(text.String(), "4 checked, 2 passed, 1 warned, 1 failed\n")
+
+	var js bytes.Buffer
+	assert.NoError(report.Write(&js, FormatJSON))
+	decoded := Report{}
+	assert.NoError(json.Unmarshal(js.Bytes(), &decoded))
+	assert.Equal(report, decoded)
+
+	var junit bytes.Buffer
+	assert.NoError(report.Write(&junit, FormatJUnit))
+	suites := junitSuites{}
+	assert.NoError(xml.Unmarshal(junit.Bytes(), &suites))
+	assert.Equal(4, suites.Tests)
+	assert.Equal(1, suites.Failures)
+	assert.Len(suites.Suites[0].Cases, 4)
+	assert.NotNil(suites.Suites[0].Cases[0].Failure)
+	assert.Nil(suites.Suites[0].Cases[1].Failure)
+
+	assert.Error(report.Write(&text, "yaml"))
+}
+
+func TestLoadInvalid(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	m := &Manifests{}
+	err := m.Load("bad.yaml", strings.NewReader("apiVersion: v1\nkind: Pod\nmetadata: [\n"), "default")
+	assert.True(errors.Is(err, common.ErrInvalidManifest))
+	assert.Contains(err.Error(), "bad.yaml#1")
+}
+
+func TestCheckInvalidPolicies(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	m := &Manifests{}
+	assert.NoError(m.Load("in.yaml", strings.NewReader(`
+apiVersion: custom.cmss.com/v1
+kind: CustomLimitRange
+metadata:
+  name: limits
+spec:
+  limitrange:
+    max:
+      ingress-bandwidth: 100M
+    min:
+      ingress-bandwidth: 1G
+---
+apiVersion: custom.cmss.com/v1
+kind: ClusterCustomLimitRange
+metadata:
+  name: teams
+spec:
+  limitrange:
+    max:
+      egress-bandwidth: 10
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+  annotations:
+    kubernetes.io/ingress-bandwidth: 10G
+`), "default"))
+
+	// the invalid policies are reported, and do not bound the pods
+	assert.Empty(m.Policies)
+	report := m.Check("default")
+	assert.Equal(2, report.Failed)
+	assert.Equal(1, report.Passed)
+	assert.Len(report.Results, 3)
+	clr := report.Results[0]
+	assert.Equal("in.yaml#1", clr.Source)
+	assert.Equal("CustomLimitRange", clr.Kind)
+	assert.Equal("default", clr.Namespace)
+	assert.Equal(policy.Deny, clr.Decision)
+	assert.Contains(clr.Reasons[0], "spec.limitrange.")
+	assert.Equal("ClusterCustomLimitRange", report.Results[1].Kind)
+	assert.Contains(report.Results[1].Reasons[0], "spec.limitrange.max.egress-bandwidth: ")
+}
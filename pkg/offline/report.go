@@ -0,0 +1,145 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package offline
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/policy"
+)
+
+// Report formats.
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatJUnit = "junit"
+)
+
+// Write writes the report in the given format: text, json or junit (JUnit XML).
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatText:
+		return r.writeText(w)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case FormatJUnit:
+		return r.writeJUnit(w)
+	}
+	return fmt.Errorf("unknown report format %q, expected text, json or junit", format)
+}
+
+// status is the outcome of a result in the text report.
+func (res *Result) status() string {
+	switch res.Decision {
+	case policy.Deny:
+		return "FAIL"
+	case policy.Warn:
+		return "WARN"
+	}
+	return "PASS"
+}
+
+func (res *Result) object() string {
+	return fmt.Sprintf("%s %s/%s", res.Kind, res.Namespace, res.Name)
+}
+
+func (r *Report) writeText(w io.Writer) error {
+	var b strings.Builder
+	for _, res := range r.Results {
+		fmt.Fprintf(&b, "%s %s (%s)", res.status(), res.object(), res.Source)
+		if res.Policy != "" {
+			fmt.Fprintf(&b, ": %s (%s)", res.Policy, res.Mode)
+		}
+		b.WriteString("\n")
+		for _, key := range res.Defaulted {
+			fmt.Fprintf(&b, "    defaulted %s\n", key)
+		}
+		for _, reason := range res.Reasons {
+			fmt.Fprintf(&b, "    %s\n", reason)
+		}
+	}
+	fmt.Fprintf(&b, "%d checked, %d passed, %d warned, %d failed\n", len(r.Results), r.Passed, r.Warned, r.Failed)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes the report as one JUnit test case per object, denied objects failing. The
+// reasons of the admitted objects are kept in the output of their test case.
+func (r *Report) writeJUnit(w io.Writer) error {
+	suite := junitSuite{Name: "bandwidth", Tests: len(r.Results), Failures: r.Failed, Cases: []junitCase{}}
+	for _, res := range r.Results {
+		c := junitCase{
+			Name:      res.Namespace + "/" + res.Name,
+			Classname: res.Kind,
+			File:      res.Source,
+		}
+		reasons := strings.Join(res.Reasons, "\n")
+		if res.Decision == policy.Deny {
+			c.Failure = &junitFailure{Message: res.object() + " is denied", Type: string(res.Decision), Text: reasons}
+		} else {
+			c.SystemOut = reasons
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	suites := junitSuites{Name: "custom-limit-range", Tests: suite.Tests, Failures: suite.Failures, Suites: []junitSuite{suite}}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/injector"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/policy"
+)
+
+// NamespaceDescription is the bandwidth policies in effect in a namespace, merged for the pods
+// with the given labels.
+type NamespaceDescription struct {
+	Namespace string
+	PodLabels map[string]string
+	Policies  []policy.Policy
+	Effective policy.Effective
+}
+
+// DescribeNamespace describes the policies in effect in a namespace and their merged range for
+// the pods with the given labels. Without labels, the range is the one of the pods no rule selects.
+func DescribeNamespace(ctx context.Context, c client.Reader, namespace string, podLabels map[string]string) (*NamespaceDescription, error) {
+	policies, _, err := injector.PoliciesFor(ctx, c, namespace)
+	if err != nil {
+		return nil, err
+	}
+	policy.SortByPriority(policies)
+	return &NamespaceDescription{
+		Namespace: namespace,
+		PodLabels: podLabels,
+		Policies:  policies,
+		Effective: policy.Merge(policies, podLabels),
+	}, nil
+}
+
+// Print writes the policies, then the effective min, default and max of every annotation.
+func (d *NamespaceDescription) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "Namespace:\t%s\n", d.Namespace)
+	fmt.Fprintf(tw, "Mode:\t%s\n", orNone(string(d.Effective.Mode)))
+	if len(d.Policies) == 0 {
+		fmt.Fprintln(tw, "Policies:\t<none>")
+		return tw.Flush()
+	}
+
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "POLICY\tPRIORITY\tMODE\tRULES")
+	for _, p := range d.Policies {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%d\n", p.String(), p.Priority, p.Mode.Effective(), len(p.Rules))
+	}
+
+	fmt.Fprintln(tw)
+	if len(d.PodLabels) == 0 {
+		fmt.Fprintln(tw, "Effective range for the pods no rule selects:")
+	} else {
+		fmt.Fprintf(tw, "Effective range for the pods labeled %s:\n", labels.Set(d.PodLabels).String())
+	}
+	fmt.Fprintln(tw, "ANNOTATION\tMIN\tDEFAULT\tMAX\tSOURCES")
+	for _, key := range policy.PodKeys {
+		b := d.Effective.Range.Get(key)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", key,
+			quantity(b.Min), quantity(b.Default), quantity(b.Max), sources(d.Effective.Sources[key]))
+	}
+	return tw.Flush()
+}
+
+// ParseLabels parses pod labels written as k1=v1,k2=v2.
+func ParseLabels(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	set, err := labels.ConvertSelectorToLabelsMap(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: labels %q: %v", common.ErrInvalidArgument, s, err)
+	}
+	return set, nil
+}
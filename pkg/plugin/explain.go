@@ -0,0 +1,209 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package plugin implements the subcommands of the kubectl-clr plugin, which explains the
+// bandwidth policies in effect for pods and namespaces. The policies are looked up as the
+// PodAnnotator webhook does, see injector.PoliciesFor.
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/injector"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/policy"
+)
+
+// Origin tells where the value of a bandwidth annotation of a pod comes from.
+type Origin string
+
+const (
+	// OriginUnset is an annotation the pod does not have.
+	OriginUnset Origin = "unset"
+	// OriginUser is a value set by the user.
+	OriginUser Origin = "user"
+	// OriginDefault is a value injected by the webhook from a default, or for a pod admitted without
+	// provenance annotations, a value equal to the default in effect.
+	OriginDefault Origin = "default"
+	// OriginClamped is a value clamped by a policy in clamp mode, the requested value is kept.
+	OriginClamped Origin = "clamped"
+)
+
+// AnnotationExplanation explains the value of one bandwidth annotation of a pod.
+type AnnotationExplanation struct {
+	Key    string
+	Value  string
+	Origin Origin
+	// Requested is the value requested by the user when it was clamped.
+	Requested  string
+	Bound      policy.Bound
+	Sources    policy.BoundSource
+	OutOfRange bool
+}
+
+// PodExplanation explains the bandwidth annotations of a pod with the policies in effect now.
+type PodExplanation struct {
+	Namespace string
+	Name      string
+	// Disabled is set when the pod opted out of the bandwidth policies.
+	Disabled bool
+	// Policy and Mode describe the merged policies in effect, if any.
+	Policy string
+	Mode   policy.Mode
+	// AdmittedBy lists the policies in effect when the pod was admitted, from its provenance
+	// annotations, and Outdated is set when the policies in effect changed since.
+	AdmittedBy  string
+	Outdated    bool
+	Annotations []AnnotationExplanation
+}
+
+// ExplainPod explains the bandwidth annotations of a pod against the policies in effect in its
+// namespace. The defaulted annotations are read from the provenance annotations of the pod. A pod
+// admitted without them does not record whether the user or the webhook set a value: a value equal
+// to the default in effect is reported as defaulted.
+func ExplainPod(ctx context.Context, c client.Reader, namespace, name string) (*PodExplanation, error) {
+	pod := &corev1.Pod{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, pod); err != nil {
+		return nil, err
+	}
+	policies, _, err := injector.PoliciesFor(ctx, c, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	e := policy.Merge(policies, pod.Labels)
+	explanation := &PodExplanation{
+		Namespace: namespace,
+		Name:      name,
+		Disabled:  policy.Disabled(pod.Annotations),
+		Policy:    e.Source,
+		Mode:      e.Mode,
+	}
+	admitted, provenance := pod.Annotations[common.ProvenancePoliciesAnnotation]
+	var defaulted []string
+	if provenance {
+		explanation.AdmittedBy = admitted
+		explanation.Outdated = admitted != policy.Provenance(policies)
+		if d := pod.Annotations[common.ProvenanceDefaultedAnnotation]; d != "" {
+			defaulted = strings.Split(d, ",")
+		}
+	}
+	for _, key := range policy.PodKeys {
+		b := e.Range.Get(key)
+		a := AnnotationExplanation{Key: key, Bound: b, Sources: e.Sources[key], Origin: OriginUnset}
+		if val, ok := pod.Annotations[key]; ok {
+			a.Value = val
+			a.OutOfRange = policy.Range{b}.OutOfRange(map[string]string{key: val})
+			switch {
+			case pod.Annotations[policy.RequestedAnnotations[key]] != "":
+				a.Origin, a.Requested = OriginClamped, pod.Annotations[policy.RequestedAnnotations[key]]
+			case provenance && slices.Contains(defaulted, key):
+				a.Origin = OriginDefault
+			case !provenance && policy.Range{b}.Defaulted(map[string]string{key: val}):
+				a.Origin = OriginDefault
+			default:
+				a.Origin = OriginUser
+			}
+		}
+		explanation.Annotations = append(explanation.Annotations, a)
+	}
+	return explanation, nil
+}
+
+// describe returns the origin of the value in words.
+func (a *AnnotationExplanation) describe() string {
+	var origin string
+	switch a.Origin {
+	case OriginUnset:
+		return "not set"
+	case OriginClamped:
+		origin = "clamped from " + a.Requested
+	case OriginDefault:
+		origin = "default of " + a.Sources.Default
+	default:
+		origin = "set by the user"
+	}
+	if a.OutOfRange {
+		origin += ", out of range"
+	}
+	return origin
+}
+
+// Print writes the explanation as a table of the annotations.
+func (e *PodExplanation) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "Pod:\t%s/%s\n", e.Namespace, e.Name)
+	fmt.Fprintf(tw, "Policies:\t%s\n", orNone(e.Policy))
+	fmt.Fprintf(tw, "Mode:\t%s\n", orNone(string(e.Mode)))
+	if e.AdmittedBy != "" {
+		admitted := e.AdmittedBy
+		if e.Outdated {
+			admitted += " (the policies changed since)"
+		}
+		fmt.Fprintf(tw, "Admitted by:\t%s\n", admitted)
+	}
+	if e.Disabled {
+		fmt.Fprintf(tw, "Disabled:\tthe pod opted out with %s=disable\n", common.WebhookPodDisable)
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "ANNOTATION\tVALUE\tORIGIN\tMIN\tDEFAULT\tMAX")
+	for _, a := range e.Annotations {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", a.Key, orDash(a.Value), a.describe(),
+			quantity(a.Bound.Min), quantity(a.Bound.Default), quantity(a.Bound.Max))
+	}
+	return tw.Flush()
+}
+
+// sources lists the policies that set the bounds, e.g. "min test/a, max test/b".
+func sources(s policy.BoundSource) string {
+	var set []string
+	for _, b := range []struct{ limit, id string }{{"min", s.Min}, {"default", s.Default}, {"max", s.Max}} {
+		if b.id != "" {
+			set = append(set, b.limit+" "+b.id)
+		}
+	}
+	return orDash(strings.Join(set, ", "))
+}
+
+func quantity(q resource.Quantity) string {
+	if q.IsZero() {
+		return "-"
+	}
+	return q.String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/policy"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+)
+
+func newClient(objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = webhook.AddToScheme(scheme)
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func newPod(namespace, name string, annotations map[string]string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Annotations: annotations}}
+}
+
+func newLimitRanges() []client.Object {
+	return []client.Object{
+		&webhook.CustomLimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "test"},
+			Spec: webhook.CustomLimitRangeSpec{Priority: 1, EnforcementMode: webhook.EnforcementModeClamp, LRange: webhook.LimitRange{
+				Type: "Pod",
+				BandwidthRange: webhook.BandwidthRange{
+					Min:     webhook.CustomItems{Ingress: resource.MustParse("10M")},
+					Default: webhook.CustomItems{Ingress: resource.MustParse("100M"), Egress: resource.MustParse("200M")},
+				},
+			}},
+		},
+		&webhook.CustomLimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "test"},
+			Spec: webhook.CustomLimitRangeSpec{EnforcementMode: webhook.EnforcementModeClamp, LRange: webhook.LimitRange{
+				Type: "Pod",
+				BandwidthRange: webhook.BandwidthRange{
+					Min: webhook.CustomItems{Ingress: resource.MustParse("1M")},
+					Max: webhook.CustomItems{Ingress: resource.MustParse("1G"), Egress: resource.MustParse("1G")},
+				},
+			}},
+		},
+	}
+}
+
+func TestExplainPod(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	c := newClient(append(newLimitRanges(), newPod("test", "web", map[string]string{
+		common.IngressBandwidthAnnotation:         "100M",
+		common.EgressBandwidthAnnotation:          "1G",
+		common.RequestedEgressBandwidthAnnotation: "5G",
+	}))...)
+
+	e, err := ExplainPod(context.Background(), c, "test", "web")
+	assert.NoError(err)
+	assert.Equal("CustomLimitRange test/a,b", e.Policy)
+	assert.Equal(policy.ModeClamp, e.Mode)
+	assert.False(e.Disabled)
+	assert.Len(e.Annotations, 2)
+
+	ingress := e.Annotations[0]
+	assert.Equal(common.IngressBandwidthAnnotation, ingress.Key)
+	assert.Equal(OriginDefault, ingress.Origin)
+	assert.Equal(policy.BoundSource{Min: "test/b", Default: "test/a", Max: "test/b"}, ingress.Sources)
+
+	egress := e.Annotations[1]
+	assert.Equal(OriginClamped, egress.Origin)
+	assert.Equal("5G", egress.Requested)
+	assert.False(egress.OutOfRange)
+
+	var out bytes.Buffer
+	assert.NoError(e.Print(&out))
+	assert.Contains(out.String(), "Pod:       test/web\n")
+	assert.Contains(out.String(), "Policies:  CustomLimitRange test/a,b\n")
+	assert.Regexp(`kubernetes.io/ingress-bandwidth\s+100M\s+default of test/a\s+1M\s+100M\s+1G`, out.String())
+	assert.Regexp(`kubernetes.io/egress-bandwidth\s+1G\s+clamped from 5G\s+-\s+200M\s+1G`, out.String())
+	assert.NotContains(out.String(), "burst")
+
+	_, err = ExplainPod(context.Background(), c, "test", "missing")
+	assert.Error(err)
+}
+
+func TestExplainPodProvenance(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	// the user set the default of a, the webhook injected the egress default of a
+	c := newClient(append(newLimitRanges(), newPod("test", "web", map[string]string{
+		common.IngressBandwidthAnnotation:    "100M",
+		common.EgressBandwidthAnnotation:     "200M",
+		common.ProvenancePoliciesAnnotation:  "test/a@0,test/b@0",
+		common.ProvenanceDefaultedAnnotation: common.EgressBandwidthAnnotation,
+	}), newPod("test", "old", map[string]string{common.ProvenancePoliciesAnnotation: "test/a@0"}))...)
+
+	e, err := ExplainPod(context.Background(), c, "test", "web")
+	assert.NoError(err)
+	assert.Equal("test/a@0,test/b@0", e.AdmittedBy)
+	assert.False(e.Outdated)
+	assert.Equal(OriginUser, e.Annotations[0].Origin)
+	assert.Equal(OriginDefault, e.Annotations[1].Origin)
+
+	var out bytes.Buffer
+	assert.NoError(e.Print(&out))
+	assert.Regexp(`Admitted by:\s+test/a@0,test/b@0\n`, out.String())
+
+	e, err = ExplainPod(context.Background(), c, "test", "old")
+	assert.NoError(err)
+	assert.True(e.Outdated)
+	out.Reset()
+	assert.NoError(e.Print(&out))
+	assert.Contains(out.String(), "test/a@0 (the policies changed since)")
+}
+
+func TestExplainPodOutOfRange(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	c := newClient(append(newLimitRanges(), newPod("test", "big", map[string]string{
+		common.IngressBandwidthAnnotation: "10G",
+		common.WebhookPodDisable:          "disable",
+	}))...)
+
+	e, err := ExplainPod(context.Background(), c, "test", "big")
+	assert.NoError(err)
+	assert.True(e.Disabled)
+	assert.Equal(OriginUser, e.Annotations[0].Origin)
+	assert.True(e.Annotations[0].OutOfRange)
+	assert.Equal("set by the user, out of range", e.Annotations[0].describe())
+}
+
+func TestDescribeNamespace(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	c := newClient(newLimitRanges()...)
+	d, err := DescribeNamespace(context.Background(), c, "test", nil)
+	assert.NoError(err)
+	assert.Len(d.Policies, 2)
+	assert.Equal("a", d.Policies[0].Name)
+	assert.Equal(policy.ModeClamp, d.Effective.Mode)
+
+	var out bytes.Buffer
+	assert.NoError(d.Print(&out))
+	assert.Regexp(`CustomLimitRange test/b\s+0\s+clamp\s+0`, out.String())
+	assert.Contains(out.String(), "Effective range for the pods no rule selects:")
+	assert.Regexp(`kubernetes.io/ingress-bandwidth\s+1M\s+100M\s+1G\s+min test/b, default test/a, max test/b`, out.String())
+
+	d, err = DescribeNamespace(context.Background(), c, "empty", map[string]string{"app": "web"})
+	assert.NoError(err)
+	out.Reset()
+	assert.NoError(d.Print(&out))
+	assert.Contains(out.String(), "Policies:   <none>")
+}
+
+func TestTop(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	done := newPod("test", "done", map[string]string{common.IngressBandwidthAnnotation: "10G"})
+	done.Status.Phase = corev1.PodSucceeded
+	c := newClient(
+		newPod("test", "small", map[string]string{common.IngressBandwidthAnnotation: "10M"}),
+		newPod("test", "none", nil),
+		newPod("test", "big", map[string]string{common.IngressBandwidthAnnotation: "1G", common.EgressBandwidthAnnotation: "1G"}),
+		newPod("other", "big", map[string]string{common.EgressBandwidthAnnotation: "2G"}),
+		done,
+	)
+
+	pods, err := Top(context.Background(), c, "test")
+	assert.NoError(err)
+	assert.Len(pods, 3)
+	assert.Equal([]string{"big", "small", "none"}, []string{pods[0].Name, pods[1].Name, pods[2].Name})
+
+	pods, err = Top(context.Background(), c, "")
+	assert.NoError(err)
+	assert.Len(pods, 4)
+	assert.Equal("other", pods[0].Namespace)
+
+	var out bytes.Buffer
+	assert.NoError(PrintTop(&out, pods, true))
+	assert.Regexp(`NAMESPACE\s+NAME\s+INGRESS\s+EGRESS\nother\s+big\s+-\s+2G\n`, out.String())
+}
+
+func TestResourceName(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	for arg, want := range map[string]string{"nginx": "nginx", "pod/nginx": "nginx", "PODS/nginx": "nginx", "po/nginx": "nginx"} {
+		name, err := ResourceName(arg, "pod", "pods", "po")
+		assert.NoError(err)
+		assert.Equal(want, name)
+	}
+	for _, arg := range []string{"deploy/nginx", "pod/", "pod/a/b", ""} {
+		_, err := ResourceName(arg, "pod", "pods", "po")
+		assert.True(errors.Is(err, common.ErrInvalidArgument), arg)
+	}
+
+	set, err := ParseLabels("app=web,tier=db")
+	assert.NoError(err)
+	assert.Equal(map[string]string{"app": "web", "tier": "db"}, set)
+	_, err = ParseLabels("app")
+	assert.True(errors.Is(err, common.ErrInvalidArgument))
+}
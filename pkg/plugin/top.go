@@ -0,0 +1,116 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/webhook"
+)
+
+// PodBandwidth is the bandwidth configured on a pod by its annotations.
+type PodBandwidth struct {
+	Namespace string
+	Name      string
+	Ingress   resource.Quantity
+	Egress    resource.Quantity
+}
+
+// total returns the ingress plus the egress bandwidth.
+func (p *PodBandwidth) total() resource.Quantity {
+	total := p.Ingress.DeepCopy()
+	total.Add(p.Egress)
+	return total
+}
+
+// Top lists the pods of a namespace, or of all namespaces when it is empty, by descending
+// configured bandwidth, ingress plus egress. Pods that reached a terminal phase are skipped, as in
+// the BandwidthQuota usage.
+func Top(ctx context.Context, c client.Reader, namespace string) ([]PodBandwidth, error) {
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	top := make([]PodBandwidth, 0, len(pods.Items))
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		b := webhook.PodBandwidth(pod.Annotations)
+		top = append(top, PodBandwidth{Namespace: pod.Namespace, Name: pod.Name, Ingress: b.Ingress, Egress: b.Egress})
+	}
+	sort.SliceStable(top, func(i, j int) bool {
+		ti, tj := top[i].total(), top[j].total()
+		if d := ti.Cmp(tj); d != 0 {
+			return d > 0
+		}
+		if top[i].Namespace != top[j].Namespace {
+			return top[i].Namespace < top[j].Namespace
+		}
+		return top[i].Name < top[j].Name
+	})
+	return top, nil
+}
+
+// PrintTop writes the pods as a table, with their namespace when allNamespaces is set.
+func PrintTop(w io.Writer, pods []PodBandwidth, allNamespaces bool) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	if allNamespaces {
+		fmt.Fprint(tw, "NAMESPACE\t")
+	}
+	fmt.Fprintln(tw, "NAME\tINGRESS\tEGRESS")
+	for i := range pods {
+		if allNamespaces {
+			fmt.Fprintf(tw, "%s\t", pods[i].Namespace)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", pods[i].Name, quantity(pods[i].Ingress), quantity(pods[i].Egress))
+	}
+	return tw.Flush()
+}
+
+// ResourceName returns the name of a resource argument written as name or type/name, where the
+// type is one of the given aliases, e.g. "pod/nginx" for the aliases of pods.
+func ResourceName(arg string, aliases ...string) (string, error) {
+	typ, name, ok := strings.Cut(arg, "/")
+	if !ok {
+		name = arg
+	} else {
+		known := false
+		for _, alias := range aliases {
+			known = known || strings.EqualFold(typ, alias)
+		}
+		if !known {
+			return "", fmt.Errorf("%w: %q, expected %s/<name>", common.ErrInvalidArgument, arg, aliases[0])
+		}
+	}
+	if name == "" || strings.Contains(name, "/") {
+		return "", fmt.Errorf("%w: %q, expected %s/<name>", common.ErrInvalidArgument, arg, aliases[0])
+	}
+	return name, nil
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+)
+
+// Decision is the outcome of the evaluation of a pod.
+type Decision string
+
+const (
+	// Admit admits the pod with the resulting annotations.
+	Admit Decision = "admit"
+	// Deny rejects the pod.
+	Deny Decision = "deny"
+	// Warn admits the pod with the resulting annotations and returns the reasons as warnings.
+	Warn Decision = "warn"
+)
+
+// RequestedAnnotations maps a bandwidth annotation to the annotation keeping the value requested
+// by the user when it is clamped.
+var RequestedAnnotations = map[string]string{
+	common.IngressBandwidthAnnotation: common.RequestedIngressBandwidthAnnotation,
+	common.EgressBandwidthAnnotation:  common.RequestedEgressBandwidthAnnotation,
+}
+
+// Violation is a bandwidth annotation value outside of the range, with the nearest bound and the
+// policy that set it.
+type Violation struct {
+	Key   string
+	Value string
+	// Limit is "max" or "min".
+	Limit  string
+	Bound  resource.Quantity
+	Source string
+}
+
+// Detail describes the bound the value violates.
+func (v Violation) Detail() string {
+	if v.Limit == "max" {
+		return fmt.Sprintf("must be at most %s, the max set by %s", v.Bound.String(), v.Source)
+	}
+	return fmt.Sprintf("must be at least %s, the min set by %s", v.Bound.String(), v.Source)
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s=%s: %s", v.Key, v.Value, v.Detail())
+}
+
+// Result is the evaluation of a pod.
+type Result struct {
+	Decision Decision
+	// Annotations are the annotations of the admitted pod, with the defaults injected and, in clamp
+	// mode, the values out of range clamped. They are nil when the pod is denied.
+	Annotations map[string]string
+	// Reasons explain the decision: the warnings of Warn, the errors of Deny, or the violations
+	// admitted in audit mode, which the caller only logs.
+	Reasons []string
+	// Cause and Errors are set on Deny: common.ErrInvalidPodBandwidthAnnotation or
+	// common.ErrInvalidPodSettingBandwidthMaxMin, and the errors on the pod annotations.
+	Cause  error
+	Errors field.ErrorList
+	// Mode and Source are those of the policy in effect, if any.
+	Mode   Mode
+	Source string
+	// Defaulted lists the annotations set to the default of the policy, Validated the annotations
+	// set by the user that the policy bounds.
+	Defaulted  []string
+	Validated  []string
+	Violations []Violation
+}
+
+// Disabled reports whether the pod opted out of the bandwidth policies.
+func Disabled(annotations map[string]string) bool {
+	return annotations[common.WebhookPodDisable] == "disable"
+}
+
+// EvaluatePod evaluates a pod against the policies in effect in its namespace, see Applicable.
+func EvaluatePod(policies []Policy, pod *corev1.Pod) Result {
+	return Evaluate(policies, pod.Labels, pod.Annotations)
+}
+
+// EvaluateTemplate evaluates the pods of a workload against the policies in effect in its namespace.
+func EvaluateTemplate(policies []Policy, template *corev1.PodTemplateSpec) Result {
+	return Evaluate(policies, template.Labels, template.Annotations)
+}
+
+// Evaluate validates the bandwidth annotations of a pod with the given labels against the merged
+// range of the policies, see Merge, and injects the defaults. Depending on the mode, values out of
+// range are denied, clamped, returned as warnings, or only reported. Pods that opted out with the
+// common.WebhookPodDisable annotation, and pods no policy applies to, are admitted unchanged.
+func Evaluate(policies []Policy, podLabels, annotations map[string]string) Result {
+	if Disabled(annotations) || len(policies) == 0 {
+		return Result{Decision: Admit, Annotations: annotations}
+	}
+	if errs := ValidateAnnotations(annotations); len(errs) > 0 {
+		return deny(common.ErrInvalidPodBandwidthAnnotation, errs)
+	}
+
+	e := Merge(policies, podLabels)
+	kinds := make(map[string]string, len(policies))
+	for i := range policies {
+		kinds[policies[i].ID()] = policies[i].Kind
+	}
+	name := func(id string) string {
+		if id == "" {
+			return ""
+		}
+		return kinds[id] + " " + id
+	}
+
+	out := make(map[string]string, len(annotations)+2)
+	for k, v := range annotations {
+		out[k] = v
+	}
+	res := Result{Decision: Admit, Annotations: out, Mode: e.Mode, Source: e.Source}
+	for _, b := range e.Range {
+		val, ok := out[b.Key]
+		if !ok {
+			if !b.Default.IsZero() {
+				out[b.Key] = b.Default.String()
+				res.Defaulted = append(res.Defaulted, b.Key)
+			}
+			continue
+		}
+		q, err := resource.ParseQuantity(val)
+		if err != nil {
+			continue
+		}
+		if !b.Max.IsZero() || !b.Min.IsZero() {
+			res.Validated = append(res.Validated, b.Key)
+		}
+		if !b.Max.IsZero() && q.Cmp(b.Max) > 0 {
+			res.Violations = append(res.Violations, Violation{Key: b.Key, Value: val, Limit: "max", Bound: b.Max, Source: name(e.Sources[b.Key].Max)})
+		} else if !b.Min.IsZero() && q.Cmp(b.Min) < 0 {
+			res.Violations = append(res.Violations, Violation{Key: b.Key, Value: val, Limit: "min", Bound: b.Min, Source: name(e.Sources[b.Key].Min)})
+		}
+	}
+
+	switch e.Mode {
+	case ModeClamp:
+		for _, v := range res.Violations {
+			out[v.Key] = v.Bound.String()
+			if requested, ok := RequestedAnnotations[v.Key]; ok {
+				out[requested] = v.Value
+			}
+			res.Reasons = append(res.Reasons, fmt.Sprintf("%s (%s): %s clamped from %s to %s", v.Source, e.Mode, v.Key, v.Value, out[v.Key]))
+		}
+	case ModeWarn, ModeAudit:
+		res.Reasons = violationMessages(e.Mode, res.Violations)
+		if e.Mode == ModeAudit {
+			return res
+		}
+	case ModeDryRun:
+		res.Annotations = annotations
+		res.Reasons = violationMessages(e.Mode, res.Violations)
+		for _, key := range res.Defaulted {
+			res.Reasons = append(res.Reasons, fmt.Sprintf("%s (dryRun): would set %s=%s", e.Source, key, out[key]))
+		}
+	default:
+		if len(res.Violations) > 0 {
+			errs := make(field.ErrorList, 0, len(res.Violations))
+			for _, v := range res.Violations {
+				errs = append(errs, field.Invalid(AnnotationPath(v.Key), v.Value, v.Detail()))
+			}
+			denied := deny(common.ErrInvalidPodSettingBandwidthMaxMin, errs)
+			denied.Mode, denied.Source, denied.Violations = res.Mode, res.Source, res.Violations
+			return denied
+		}
+	}
+	if len(res.Reasons) > 0 {
+		res.Decision = Warn
+	}
+	return res
+}
+
+func deny(cause error, errs field.ErrorList) Result {
+	res := Result{Decision: Deny, Cause: cause, Errors: errs}
+	for _, err := range errs {
+		res.Reasons = append(res.Reasons, err.Error())
+	}
+	return res
+}
+
+func violationMessages(mode Mode, violations []Violation) []string {
+	var messages []string
+	for _, v := range violations {
+		messages = append(messages, fmt.Sprintf("%s (%s): %s", v.Source, mode, v.String()))
+	}
+	return messages
+}
+
+// ProvenanceAnnotations are the annotations recording on a pod the policies that shaped it, see
+// WithProvenance.
+var ProvenanceAnnotations = []string{
+	common.ProvenancePoliciesAnnotation,
+	common.ProvenanceDefaultedAnnotation,
+	common.ProvenanceValidatedAnnotation,
+}
+
+// WithProvenance returns the annotations of an admitted pod with the provenance annotations: the
+// policies in effect with their generation, the annotations defaulted and the annotations validated.
+// Provenance annotations set by the user are dropped, so that they can be trusted. The annotations
+// are returned unchanged in dryRun mode, which leaves pods as they are.
+func WithProvenance(policies []Policy, res Result) map[string]string {
+	if res.Mode == ModeDryRun {
+		return res.Annotations
+	}
+	out := make(map[string]string, len(res.Annotations)+len(ProvenanceAnnotations))
+	for k, v := range res.Annotations {
+		out[k] = v
+	}
+	for _, key := range ProvenanceAnnotations {
+		delete(out, key)
+	}
+	if len(policies) == 0 {
+		return out
+	}
+
+	out[common.ProvenancePoliciesAnnotation] = Provenance(policies)
+	if len(res.Defaulted) > 0 {
+		out[common.ProvenanceDefaultedAnnotation] = strings.Join(res.Defaulted, ",")
+	}
+	if len(res.Validated) > 0 {
+		out[common.ProvenanceValidatedAnnotation] = strings.Join(res.Validated, ",")
+	}
+	return out
+}
+
+// Provenance returns the value of the policies provenance annotation for the policies: by priority,
+// the ID of every policy with its generation, e.g. "test/a@3,global@1".
+func Provenance(policies []Policy) string {
+	sorted := make([]Policy, len(policies))
+	copy(sorted, policies)
+	SortByPriority(sorted)
+	ids := make([]string, 0, len(sorted))
+	for i := range sorted {
+		ids = append(ids, fmt.Sprintf("%s@%d", sorted[i].ID(), sorted[i].Generation))
+	}
+	return strings.Join(ids, ",")
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+)
+
+func ingress(min, def, max string) Range {
+	b := Bound{Key: common.IngressBandwidthAnnotation}
+	for _, q := range []struct {
+		val string
+		to  *resource.Quantity
+	}{{min, &b.Min}, {def, &b.Default}, {max, &b.Max}} {
+		if q.val != "" {
+			*q.to = resource.MustParse(q.val)
+		}
+	}
+	return Range{b}
+}
+
+func TestEvaluate(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	policies := []Policy{
+		{Kind: "CustomLimitRange", Namespace: "test", Name: "a", Priority: 1, Range: ingress("10M", "100M", "1G")},
+		{Kind: "CustomLimitRange", Namespace: "test", Name: "b", Range: ingress("", "200M", "500M"), Rules: []Rule{
+			{Selector: labels.SelectorFromSet(labels.Set{"app": "db"}), Range: ingress("", "", "2G")},
+		}},
+	}
+
+	// the default of the highest priority policy, the tightest max
+	res := Evaluate(policies, nil, map[string]string{})
+	assert.Equal(Admit, res.Decision)
+	assert.Equal(ModeEnforce, res.Mode)
+	assert.Equal("CustomLimitRange test/a,b", res.Source)
+	assert.Equal(map[string]string{common.IngressBandwidthAnnotation: "100M"}, res.Annotations)
+	assert.Equal([]string{common.IngressBandwidthAnnotation}, res.Defaulted)
+
+	res = Evaluate(policies, nil, map[string]string{common.IngressBandwidthAnnotation: "800M"})
+	assert.Equal(Deny, res.Decision)
+	assert.Equal(common.ErrInvalidPodSettingBandwidthMaxMin, res.Cause)
+	assert.Nil(res.Annotations)
+	assert.Len(res.Errors, 1)
+	assert.Equal([]string{"metadata.annotations[kubernetes.io/ingress-bandwidth]: Invalid value: \"800M\": must be at most 500M, the max set by CustomLimitRange test/b"}, res.Reasons)
+
+	// the rule of b lifts its max for db pods
+	res = Evaluate(policies, map[string]string{"app": "db"}, map[string]string{common.IngressBandwidthAnnotation: "800M"})
+	assert.Equal(Admit, res.Decision)
+
+	res = Evaluate(policies, nil, map[string]string{common.IngressBandwidthAnnotation: "fast"})
+	assert.Equal(Deny, res.Decision)
+	assert.Equal(common.ErrInvalidPodBandwidthAnnotation, res.Cause)
+
+	res = Evaluate(policies, nil, map[string]string{common.IngressBandwidthAnnotation: "800M", common.WebhookPodDisable: "disable"})
+	assert.Equal(Admit, res.Decision)
+	assert.Equal("800M", res.Annotations[common.IngressBandwidthAnnotation])
+
+	res = Evaluate(nil, nil, map[string]string{common.IngressBandwidthAnnotation: "800M"})
+	assert.Equal(Admit, res.Decision)
+	assert.Empty(res.Reasons)
+
+	// values are only parsed when a policy applies
+	res = Evaluate(nil, nil, map[string]string{common.IngressBandwidthAnnotation: "fast"})
+	assert.Equal(Admit, res.Decision)
+	assert.Equal("fast", res.Annotations[common.IngressBandwidthAnnotation])
+}
+
+func TestEvaluateModes(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	in := map[string]string{common.IngressBandwidthAnnotation: "2G"}
+	evaluate := func(mode Mode) Result {
+		return Evaluate([]Policy{{Kind: "ClusterCustomLimitRange", Name: "c", Mode: mode, Range: ingress("", "", "1G")}}, nil, in)
+	}
+
+	res := evaluate(ModeClamp)
+	assert.Equal(Warn, res.Decision)
+	assert.Equal("1G", res.Annotations[common.IngressBandwidthAnnotation])
+	assert.Equal("2G", res.Annotations[common.RequestedIngressBandwidthAnnotation])
+	assert.Equal([]string{"ClusterCustomLimitRange c (clamp): kubernetes.io/ingress-bandwidth clamped from 2G to 1G"}, res.Reasons)
+
+	res = evaluate(ModeWarn)
+	assert.Equal(Warn, res.Decision)
+	assert.Equal(in, res.Annotations)
+	assert.Equal([]string{"ClusterCustomLimitRange c (warn): kubernetes.io/ingress-bandwidth=2G: must be at most 1G, the max set by ClusterCustomLimitRange c"}, res.Reasons)
+
+	res = evaluate(ModeAudit)
+	assert.Equal(Admit, res.Decision)
+	assert.Len(res.Violations, 1)
+	assert.Len(res.Reasons, 1)
+
+	res = evaluate(ModeDryRun)
+	assert.Equal(Warn, res.Decision)
+	assert.Equal(in, res.Annotations)
+
+	// the input is never modified
+	assert.Equal(map[string]string{common.IngressBandwidthAnnotation: "2G"}, in)
+}
+
+func TestEvaluateObjects(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	policies := []Policy{{Kind: "CustomLimitRange", Namespace: "test", Name: "a", Range: ingress("", "100M", "")}}
+	meta := metav1.ObjectMeta{Labels: map[string]string{"app": "web"}, Annotations: map[string]string{"a": "b"}}
+
+	res := EvaluatePod(policies, &corev1.Pod{ObjectMeta: meta})
+	assert.Equal("100M", res.Annotations[common.IngressBandwidthAnnotation])
+	assert.Equal("b", res.Annotations["a"])
+	assert.Equal(res, EvaluateTemplate(policies, &corev1.PodTemplateSpec{ObjectMeta: meta}))
+}
+
+func TestWithProvenance(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	policies := []Policy{
+		{Kind: "ClusterCustomLimitRange", Name: "global", Generation: 1, Range: ingress("", "", "2G")},
+		{Kind: "CustomLimitRange", Namespace: "test", Name: "a", Generation: 3, Priority: 1, Range: ingress("10M", "100M", "1G")},
+	}
+	assert.Equal("test/a@3,global@1", Provenance(policies))
+
+	res := Evaluate(policies, nil, map[string]string{common.ProvenanceValidatedAnnotation: "forged"})
+	assert.Equal(map[string]string{
+		common.IngressBandwidthAnnotation:    "100M",
+		common.ProvenancePoliciesAnnotation:  "test/a@3,global@1",
+		common.ProvenanceDefaultedAnnotation: common.IngressBandwidthAnnotation,
+	}, WithProvenance(policies, res))
+
+	res = Evaluate(policies, nil, map[string]string{common.IngressBandwidthAnnotation: "200M"})
+	assert.Equal([]string{common.IngressBandwidthAnnotation}, res.Validated)
+	assert.Equal(common.IngressBandwidthAnnotation, WithProvenance(policies, res)[common.ProvenanceValidatedAnnotation])
+
+	// no policy, no provenance
+	res = Evaluate(nil, nil, map[string]string{common.ProvenancePoliciesAnnotation: "forged"})
+	assert.Empty(WithProvenance(nil, res))
+
+	// dryRun leaves the pod as it is
+	policies[1].Mode = ModeDryRun
+	res = Evaluate(policies[1:], nil, map[string]string{})
+	assert.Equal(res.Annotations, WithProvenance(policies[1:], res))
+	assert.NotContains(res.Annotations, common.ProvenancePoliciesAnnotation)
+}
+
+func TestApplicable(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	policies := []Policy{
+		{Kind: "ClusterCustomLimitRange", Name: "b"},
+		{Kind: "ClusterCustomLimitRange", Name: "a", NamespaceSelector: labels.SelectorFromSet(labels.Set{"tier": "tenant"})},
+		{Kind: "CustomLimitRange", Namespace: "test", Name: "a"},
+	}
+	assert.Equal([]Policy{policies[2]}, Applicable(policies, "test", nil))
+	assert.Equal([]Policy{policies[1]}, Applicable(policies, "other", map[string]string{"tier": "tenant"}))
+	assert.Equal([]Policy{policies[0]}, Applicable(policies, "other", nil))
+	assert.Empty(Applicable(policies[1:2], "other", nil))
+}
+
+func TestValidateRange(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	assert.Empty(ValidateRange(ingress("1M", "10M", "1G")))
+	assert.ErrorIs(ValidateQuantity(resource.MustParse("100")), common.ErrInvalidBandwidthRange)
+
+	errs := ValidateRange(ingress("2G", "", "1G"))
+	assert.Len(errs, 1)
+	assert.Equal(common.ErrInvalidBandwidthMaxMin, errs[0].Err)
+	assert.Equal("min kubernetes.io/ingress-bandwidth 2G: must be less than or equal to max 1G", errs[0].Error())
+
+	r := Range{
+		{Key: common.IngressBandwidthAnnotation, Max: resource.MustParse("1G")},
+		{Key: common.IngressBurstKey, Max: resource.MustParse("1k")},
+	}
+	errs = ValidateRange(r)
+	assert.Len(errs, 1)
+	assert.Equal(common.IngressBurstKey, errs[0].Key)
+	assert.Equal(common.ErrInvalidBurstRate, errs[0].Err)
+
+	assert.Equal(ModeEnforce, Mode("").Effective())
+	assert.Equal(ModeWarn, ModeAudit.Stricter(ModeWarn))
+	assert.Equal(ModeEnforce, ModeWarn.Stricter(""))
+	assert.Equal(ModeAudit, ModeAudit.Stricter(ModeDryRun))
+	assert.Equal(ModeClamp, ModeClamp.Stricter(ModeWarn))
+	assert.False(Mode("strict").Valid())
+}
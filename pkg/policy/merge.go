@@ -0,0 +1,144 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// BoundSource names the policies, by ID, that supplied the bounds of one pod annotation in a
+// merged range. A bound that is not set has no source.
+type BoundSource struct {
+	Min, Default, Max string
+}
+
+// Effective is the merged policy in effect for a pod.
+type Effective struct {
+	Range Range
+	// Sources holds the BoundSource of every annotation of Range.
+	Sources map[string]BoundSource
+	Mode    Mode
+	// Source describes the merged policies, e.g. "CustomLimitRange test/a,b".
+	Source string
+}
+
+// SortByPriority orders policies by descending priority, then by name.
+func SortByPriority(policies []Policy) {
+	sort.SliceStable(policies, func(i, j int) bool {
+		if policies[i].Priority != policies[j].Priority {
+			return policies[i].Priority > policies[j].Priority
+		}
+		return policies[i].Name < policies[j].Name
+	})
+}
+
+// Merge merges policies, e.g. the CustomLimitRanges of one namespace, into the range in effect for
+// a pod with the given labels. Each policy contributes the range of its first matching rule.
+// Per direction and for rates and bursts alike, the tightest max and the loosest min win: a min left
+// unset by any policy stays unset. The default comes from the highest priority policy that sets one
+// (ties broken by name) and is clamped into the merged range. The strictest mode wins.
+func Merge(policies []Policy, podLabels map[string]string) Effective {
+	if len(policies) == 0 {
+		return Effective{}
+	}
+
+	sorted := make([]Policy, len(policies))
+	copy(sorted, policies)
+	SortByPriority(sorted)
+
+	ranges := make([]Range, 0, len(sorted))
+	for i := range sorted {
+		ranges = append(ranges, sorted[i].RangeFor(podLabels))
+	}
+	source := func(i int) string {
+		if i < 0 {
+			return ""
+		}
+		return sorted[i].ID()
+	}
+
+	merged := Effective{
+		Range:   make(Range, 0, len(Keys)),
+		Sources: make(map[string]BoundSource, len(Keys)),
+		Mode:    MergeModes(policies),
+		Source:  describe(policies),
+	}
+	for _, key := range Keys {
+		b, maxAt, minAt, defAt := mergeKey(ranges, key)
+		merged.Range = append(merged.Range, b)
+		merged.Sources[key] = BoundSource{Min: source(minAt), Default: source(defAt), Max: source(maxAt)}
+	}
+	return merged
+}
+
+// MergeModes returns the strictest mode of the policies.
+func MergeModes(policies []Policy) Mode {
+	mode := ModeDryRun
+	for i := range policies {
+		mode = mode.Stricter(policies[i].Mode)
+	}
+	return mode
+}
+
+// describe names the policies by the kind and the namespace of the first one, then their names.
+func describe(policies []Policy) string {
+	names := make([]string, 0, len(policies))
+	for i := range policies {
+		names = append(names, policies[i].Name)
+	}
+	s := policies[0].Kind + " "
+	if ns := policies[0].Namespace; ns != "" {
+		s += ns + "/"
+	}
+	return s + strings.Join(names, ",")
+}
+
+// mergeKey merges the bound of one annotation of the ranges and returns the index of the range that
+// supplied each of max, min and default, or -1 when it is not set.
+func mergeKey(ranges []Range, key string) (b Bound, maxAt, minAt, defAt int) {
+	b.Key = key
+	maxAt, minAt, defAt = -1, -1, -1
+	minSet := true
+	for i, r := range ranges {
+		rb := r.Get(key)
+		if m := rb.Max; !m.IsZero() && (b.Max.IsZero() || m.Cmp(b.Max) < 0) {
+			b.Max, maxAt = m.DeepCopy(), i
+		}
+		if m := rb.Min; m.IsZero() {
+			minSet = false
+		} else if i == 0 || m.Cmp(b.Min) < 0 {
+			b.Min, minAt = m.DeepCopy(), i
+		}
+		if d := rb.Default; !d.IsZero() && b.Default.IsZero() {
+			b.Default, defAt = d.DeepCopy(), i
+		}
+	}
+	if !minSet {
+		b.Min, minAt = resource.Quantity{}, -1
+	}
+
+	if !b.Default.IsZero() && !b.Max.IsZero() && b.Default.Cmp(b.Max) > 0 {
+		b.Default = b.Max.DeepCopy()
+	}
+	if !b.Default.IsZero() && !b.Min.IsZero() && b.Default.Cmp(b.Min) < 0 {
+		b.Default = b.Min.DeepCopy()
+	}
+	return b, maxAt, minAt, defAt
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+)
+
+// networkBandwidth is the CNI bandwidth plugin runtime config of a Multus network: rates in bit/s, bursts in bits.
+type networkBandwidth struct {
+	IngressRate  int64 `json:"ingressRate"`
+	IngressBurst int64 `json:"ingressBurst"`
+	EgressRate   int64 `json:"egressRate"`
+	EgressBurst  int64 `json:"egressBurst"`
+}
+
+// networkFields maps the keys of the bounds of a range to the fields of a networkBandwidth.
+var networkFields = []struct {
+	key, name string
+	get       func(*networkBandwidth) *int64
+}{
+	{common.IngressBandwidthAnnotation, "ingressRate", func(b *networkBandwidth) *int64 { return &b.IngressRate }},
+	{common.EgressBandwidthAnnotation, "egressRate", func(b *networkBandwidth) *int64 { return &b.EgressRate }},
+	{common.IngressBurstKey, "ingressBurst", func(b *networkBandwidth) *int64 { return &b.IngressBurst }},
+	{common.EgressBurstKey, "egressBurst", func(b *networkBandwidth) *int64 { return &b.EgressBurst }},
+}
+
+// annotations returns the bandwidth keyed as the bounds of a range, so that Evaluate validates it.
+func (b *networkBandwidth) annotations() map[string]string {
+	an := map[string]string{}
+	if b == nil {
+		return an
+	}
+	for _, f := range networkFields {
+		if v := *f.get(b); v != 0 {
+			an[f.key] = resource.NewQuantity(v, resource.DecimalSI).String()
+		}
+	}
+	return an
+}
+
+// networkSelection is an element of the common.MultusNetworksAnnotation.
+type networkSelection struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace,omitempty"`
+	Interface string            `json:"interface,omitempty"`
+	Bandwidth *networkBandwidth `json:"bandwidth,omitempty"`
+}
+
+// parseNetworks parses the common.MultusNetworksAnnotation, JSON or namespace/name@interface, also as raw JSON fields.
+func parseNetworks(value string) ([]map[string]json.RawMessage, []networkSelection, error) {
+	var raws []map[string]json.RawMessage
+	var elements []networkSelection
+	if strings.HasPrefix(strings.TrimSpace(value), "[") {
+		if err := json.Unmarshal([]byte(value), &raws); err != nil {
+			return nil, nil, err
+		}
+		if err := json.Unmarshal([]byte(value), &elements); err != nil {
+			return nil, nil, err
+		}
+		return raws, elements, nil
+	}
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		var element networkSelection
+		element.Name, element.Interface, _ = strings.Cut(item, "@")
+		if namespace, name, ok := strings.Cut(element.Name, "/"); ok {
+			element.Namespace, element.Name = namespace, name
+		}
+		if element.Name == "" {
+			return nil, nil, fmt.Errorf("invalid network %q, expected namespace/name@interface", item)
+		}
+		raw := map[string]json.RawMessage{}
+		for k, v := range map[string]string{"name": element.Name, "namespace": element.Namespace, "interface": element.Interface} {
+			if v != "" {
+				raw[k], _ = json.Marshal(v)
+			}
+		}
+		raws = append(raws, raw)
+		elements = append(elements, element)
+	}
+	return raws, elements, nil
+}
+
+// EvaluateNetworks evaluates the bandwidth of the Multus networks of a pod against the ranges of the policies, see ForNetwork.
+func EvaluateNetworks(policies []Policy, namespace string, annotations map[string]string) Result {
+	res := Result{Decision: Admit, Annotations: annotations}
+	value, ok := annotations[common.MultusNetworksAnnotation]
+	if !ok || Disabled(annotations) {
+		return res
+	}
+	var networked []Policy
+	for i := range policies {
+		if len(policies[i].Networks) > 0 {
+			networked = append(networked, policies[i])
+		}
+	}
+	if len(networked) == 0 {
+		return res
+	}
+
+	path := AnnotationPath(common.MultusNetworksAnnotation)
+	raws, elements, err := parseNetworks(value)
+	if err != nil {
+		mode := MergeModes(networked)
+		if mode == ModeEnforce {
+			denied := deny(common.ErrInvalidNetworkBandwidth, field.ErrorList{
+				field.Invalid(path, value, fmt.Sprintf("%v, the bandwidth of the networks cannot be validated", err)),
+			})
+			denied.Mode = mode
+			return denied
+		}
+		res.Decision, res.Mode = Warn, mode
+		res.Reasons = []string{fmt.Sprintf("%s: %v, the bandwidth of the networks is not validated", path, err)}
+		return res
+	}
+
+	var errs field.ErrorList
+	var evaluated []Policy
+	changed := false
+	for i, element := range elements {
+		ns := element.Namespace
+		if ns == "" {
+			ns = namespace
+		}
+		bounding := ForNetwork(policies, ns+"/"+element.Name)
+		if len(bounding) == 0 {
+			continue
+		}
+		evaluated = append(evaluated, bounding...)
+
+		// the messages name the fields of the network selection element instead of the pod annotations
+		bandwidthPath := path.Index(i).Child("bandwidth")
+		fieldPaths := make(map[string]string, len(networkFields))
+		replace := make([]string, 0, 2*len(networkFields))
+		for _, f := range networkFields {
+			fieldPaths[AnnotationPath(f.key).String()] = bandwidthPath.Child(f.name).String()
+			replace = append(replace, f.key, bandwidthPath.Child(f.name).String())
+		}
+		replacer := strings.NewReplacer(replace...)
+
+		r := Evaluate(bounding, nil, element.Bandwidth.annotations())
+		if res.Source == "" {
+			res.Source = r.Source
+		}
+		for _, v := range r.Violations {
+			v.Key = replacer.Replace(v.Key)
+			res.Violations = append(res.Violations, v)
+		}
+		for _, key := range r.Defaulted {
+			res.Defaulted = append(res.Defaulted, replacer.Replace(key))
+		}
+		if r.Decision == Deny {
+			for _, e := range r.Errors {
+				moved := *e
+				moved.Field = bandwidthPath.String()
+				if p, ok := fieldPaths[e.Field]; ok {
+					moved.Field = p
+				}
+				errs = append(errs, &moved)
+			}
+			continue
+		}
+		if r.Decision == Warn {
+			for _, reason := range r.Reasons {
+				res.Reasons = append(res.Reasons, replacer.Replace(reason))
+			}
+		}
+		if r.Mode == ModeDryRun {
+			continue
+		}
+
+		var current networkBandwidth
+		if element.Bandwidth != nil {
+			current = *element.Bandwidth
+		}
+		bandwidth := networkBandwidthFrom(r.Annotations, Merge(bounding, nil).Range)
+		if bandwidth == current {
+			continue
+		}
+		// a struct of integers always marshals
+		raws[i]["bandwidth"], _ = json.Marshal(bandwidth)
+		changed = true
+	}
+	if len(evaluated) > 0 {
+		res.Mode = MergeModes(evaluated)
+	}
+	if len(errs) > 0 {
+		denied := deny(common.ErrInvalidNetworkBandwidth, errs)
+		denied.Mode, denied.Source, denied.Violations = res.Mode, res.Source, res.Violations
+		return denied
+	}
+	if len(res.Reasons) > 0 {
+		res.Decision = Warn
+	}
+	if !changed {
+		return res
+	}
+
+	// the raw fields were parsed from JSON, or marshalled from strings
+	marshalled, _ := json.Marshal(raws)
+	out := make(map[string]string, len(annotations))
+	for k, v := range annotations {
+		out[k] = v
+	}
+	out[common.MultusNetworksAnnotation] = string(marshalled)
+	res.Annotations = out
+	return res
+}
+
+// networkBandwidthFrom returns the bandwidth of a network from the evaluated annotations, with a burst for every rate.
+func networkBandwidthFrom(an map[string]string, r Range) networkBandwidth {
+	var b networkBandwidth
+	for _, f := range networkFields {
+		if q, err := resource.ParseQuantity(an[f.key]); err == nil {
+			*f.get(&b) = q.Value()
+		}
+	}
+	for _, d := range []struct {
+		rate, burst *int64
+		burstKey    string
+	}{
+		{&b.IngressRate, &b.IngressBurst, common.IngressBurstKey},
+		{&b.EgressRate, &b.EgressBurst, common.EgressBurstKey},
+	} {
+		if *d.rate == 0 || *d.burst != 0 {
+			continue
+		}
+		bound := r.Get(d.burstKey)
+		*d.burst = math.MaxUint32
+		if !bound.Max.IsZero() && bound.Max.Value() < *d.burst {
+			*d.burst = bound.Max.Value()
+		}
+		*d.burst = max(*d.burst, bound.Min.Value(), (*d.rate+999)/1000)
+	}
+	return b
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+)
+
+func TestParseNetworks(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	raws, elements, err := parseNetworks(" storage, infra/data-plane@net2 ,")
+	assert.NoError(err)
+	assert.Equal([]networkSelection{{Name: "storage"}, {Name: "data-plane", Namespace: "infra", Interface: "net2"}}, elements)
+	assert.Len(raws, 2)
+	assert.Equal(`"net2"`, string(raws[1]["interface"]))
+
+	raws, elements, err = parseNetworks(`[{"name":"storage","ips":["10.0.0.1"],"bandwidth":{"ingressRate":1000}}]`)
+	assert.NoError(err)
+	assert.Equal(int64(1000), elements[0].Bandwidth.IngressRate)
+	assert.Equal(`["10.0.0.1"]`, string(raws[0]["ips"]))
+
+	_, _, err = parseNetworks(`[{"name":`)
+	assert.Error(err)
+	_, _, err = parseNetworks("infra/@net1")
+	assert.Error(err)
+}
+
+func TestNetworkBandwidthFrom(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	an := map[string]string{common.IngressBandwidthAnnotation: "10M", common.EgressBandwidthAnnotation: "100G"}
+
+	// without bounds, the burst of a rate is the largest the kernel accepts
+	b := networkBandwidthFrom(an, nil)
+	assert.Equal(int64(math.MaxUint32), b.IngressBurst)
+	assert.Equal(int64(math.MaxUint32), b.EgressBurst)
+
+	// the max burst bounds it, the min burst and 1ms of traffic at the rate come first
+	b = networkBandwidthFrom(an, Range{
+		{Key: common.IngressBurstKey, Min: resource.MustParse("1M"), Max: resource.MustParse("2M")},
+		{Key: common.EgressBurstKey, Max: resource.MustParse("1M")},
+	})
+	assert.Equal(int64(2000000), b.IngressBurst)
+	assert.Equal(int64(100000000), b.EgressBurst)
+	b = networkBandwidthFrom(an, Range{{Key: common.IngressBurstKey, Min: resource.MustParse("5G"), Max: resource.MustParse("1M")}})
+	assert.Equal(int64(5000000000), b.IngressBurst)
+
+	// a burst set or defaulted is kept
+	an[common.IngressBurstKey] = "20k"
+	assert.Equal(int64(20000), networkBandwidthFrom(an, nil).IngressBurst)
+}
+
+func TestEvaluateNetworks(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	policies := []Policy{{Kind: "CustomLimitRange", Namespace: "test", Name: "a", Mode: ModeWarn, Range: ingress("", "100M", "1G"),
+		Networks: []NetworkRange{{Network: "test/storage", Range: ingress("", "5G", "10G")}}}}
+
+	// pods without networks, and networks no policy bounds, are left unchanged
+	in := map[string]string{common.IngressBandwidthAnnotation: "20G"}
+	assert.Equal(Result{Decision: Admit, Annotations: in}, EvaluateNetworks(policies, "test", in))
+	in = map[string]string{common.MultusNetworksAnnotation: "other/storage"}
+	res := EvaluateNetworks(policies, "test", in)
+	assert.Equal(Admit, res.Decision)
+	assert.Equal(in, res.Annotations)
+
+	// the violations name the fields of the network selection element
+	res = EvaluateNetworks(policies, "test", map[string]string{
+		common.MultusNetworksAnnotation: `[{"name":"storage","bandwidth":{"ingressRate":20000000000,"ingressBurst":1000000000}}]`,
+	})
+	assert.Equal(Warn, res.Decision)
+	assert.Equal(ModeWarn, res.Mode)
+	assert.Equal("CustomLimitRange test/a", res.Source)
+	assert.Len(res.Violations, 1)
+	assert.Equal("metadata.annotations[k8s.v1.cni.cncf.io/networks][0].bandwidth.ingressRate", res.Violations[0].Key)
+	assert.Equal([]string{"CustomLimitRange test/a (warn): metadata.annotations[k8s.v1.cni.cncf.io/networks][0].bandwidth.ingressRate=20G: must be at most 10G, the max set by CustomLimitRange test/a"}, res.Reasons)
+
+	// the defaults are written into the network selection element
+	res = EvaluateNetworks(policies, "test", map[string]string{common.MultusNetworksAnnotation: "test/storage@net1"})
+	assert.Equal(Admit, res.Decision)
+	assert.Equal([]string{"metadata.annotations[k8s.v1.cni.cncf.io/networks][0].bandwidth.ingressRate"}, res.Defaulted)
+	assert.JSONEq(`[{"name":"storage","namespace":"test","interface":"net1","bandwidth":{"ingressRate":5000000000,"ingressBurst":4294967295,"egressRate":0,"egressBurst":0}}]`,
+		res.Annotations[common.MultusNetworksAnnotation])
+
+	// an annotation Multus cannot parse either is only reported, and denied in enforce mode
+	res = EvaluateNetworks(policies, "test", map[string]string{common.MultusNetworksAnnotation: `[{"name":`})
+	assert.Equal(Warn, res.Decision)
+	assert.Len(res.Reasons, 1)
+	policies[0].Mode = ModeEnforce
+	res = EvaluateNetworks(policies, "test", map[string]string{common.MultusNetworksAnnotation: `[{"name":`})
+	assert.Equal(Deny, res.Decision)
+	assert.ErrorIs(res.Cause, common.ErrInvalidNetworkBandwidth)
+	assert.Len(res.Errors, 1)
+	assert.Equal("metadata.annotations[k8s.v1.cni.cncf.io/networks]", res.Errors[0].Field)
+}
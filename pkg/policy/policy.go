@@ -0,0 +1,242 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package policy evaluates the bandwidth of pods against bandwidth policies, such as the
+// CustomLimitRanges of a namespace, without any access to the Kubernetes API: the caller lists
+// the policies and hands them over with the pod. The admission webhooks, the controllers and
+// offline tools all evaluate pods here, so that they share the same semantics.
+package policy
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+)
+
+// Keys are the bounds of a range: the ingress and egress rates, named by their pod annotation, then
+// the ingress and egress bursts, which only bound networks, see common.IngressBurstKey.
+var Keys = []string{
+	common.IngressBandwidthAnnotation,
+	common.EgressBandwidthAnnotation,
+	common.IngressBurstKey,
+	common.EgressBurstKey,
+}
+
+// PodKeys are the pod annotations a policy covers: the ingress and egress rates.
+var PodKeys = Keys[:2]
+
+// Mode is how a policy handles the pods outside of its range.
+type Mode string
+
+const (
+	// ModeEnforce rejects the pods outside of the range.
+	ModeEnforce Mode = "enforce"
+	// ModeClamp rewrites the values outside of the range to the nearest bound.
+	ModeClamp Mode = "clamp"
+	// ModeWarn admits the pods outside of the range with a warning.
+	ModeWarn Mode = "warn"
+	// ModeAudit admits the pods outside of the range, the caller only logs them.
+	ModeAudit Mode = "audit"
+	// ModeDryRun leaves the pods unchanged and reports what would be done.
+	ModeDryRun Mode = "dryRun"
+)
+
+// strictness orders modes from the most permissive to the strictest.
+var strictness = map[Mode]int{
+	ModeDryRun:  0,
+	ModeAudit:   1,
+	ModeWarn:    2,
+	ModeClamp:   3,
+	ModeEnforce: 4,
+}
+
+// Valid reports whether the mode is one of the known modes.
+func (m Mode) Valid() bool {
+	_, ok := strictness[m]
+	return ok
+}
+
+// Effective returns the mode, defaulting an empty or unknown value to enforce.
+func (m Mode) Effective() Mode {
+	if !m.Valid() {
+		return ModeEnforce
+	}
+	return m
+}
+
+// Stricter returns the stricter of two modes.
+func (m Mode) Stricter(o Mode) Mode {
+	if strictness[o.Effective()] > strictness[m.Effective()] {
+		return o.Effective()
+	}
+	return m.Effective()
+}
+
+// Bound is the range of one bandwidth pod annotation. A zero quantity is not set.
+type Bound struct {
+	Key               string
+	Min, Default, Max resource.Quantity
+}
+
+// Range holds the bounds of the pod annotations, usually one per key of Keys, in that order.
+type Range []Bound
+
+// Get returns the bound of an annotation, with only its key set when the range has none.
+func (r Range) Get(key string) Bound {
+	for _, b := range r {
+		if b.Key == key {
+			return b
+		}
+	}
+	return Bound{Key: key}
+}
+
+// OutOfRange reports whether a bandwidth annotation of a pod is outside of the range.
+// Annotations that are not quantities are out of range.
+func (r Range) OutOfRange(annotations map[string]string) bool {
+	for _, b := range r {
+		val, ok := annotations[b.Key]
+		if !ok {
+			continue
+		}
+		q, err := resource.ParseQuantity(val)
+		if err != nil {
+			return true
+		}
+		if (!b.Max.IsZero() && q.Value() > b.Max.Value()) || (!b.Min.IsZero() && q.Value() < b.Min.Value()) {
+			return true
+		}
+	}
+	return false
+}
+
+// Defaulted reports whether a bandwidth annotation of a pod carries exactly the default of the range.
+func (r Range) Defaulted(annotations map[string]string) bool {
+	for _, b := range r {
+		val, ok := annotations[b.Key]
+		if !ok || b.Default.IsZero() {
+			continue
+		}
+		if q, err := resource.ParseQuantity(val); err == nil && q.Cmp(b.Default) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Rule is a range for the pods its selector matches. A nil selector matches every pod.
+type Rule struct {
+	Selector labels.Selector
+	Range    Range
+}
+
+// Policy is a bandwidth policy, e.g. a CustomLimitRange, or a ClusterCustomLimitRange when it
+// has no namespace.
+type Policy struct {
+	// Kind, Namespace and Name identify the policy in the sources and the messages.
+	Kind      string
+	Namespace string
+	Name      string
+	// Generation is the generation of the policy object, recorded in the provenance of the pods.
+	Generation int64
+	// NamespaceSelector picks the namespaces of a cluster policy. A nil selector matches every namespace.
+	NamespaceSelector labels.Selector
+	Priority          int32
+	Mode              Mode
+	// Rules are tried in order, the first one matching a pod applies.
+	Rules []Rule
+	// Range applies to the pods no rule matches.
+	Range Range
+	// Networks bound the interfaces of the pods attached to Multus secondary networks.
+	Networks []NetworkRange
+}
+
+// NetworkRange is the range of the interfaces attached to a Multus secondary network, by the
+// namespace/name of its NetworkAttachmentDefinition.
+type NetworkRange struct {
+	Network string
+	Range   Range
+}
+
+// ID returns the namespace/name of the policy, or its name when it has no namespace.
+func (p *Policy) ID() string {
+	if p.Namespace == "" {
+		return p.Name
+	}
+	return p.Namespace + "/" + p.Name
+}
+
+// String returns the kind and the ID of the policy, e.g. "CustomLimitRange test/a".
+func (p *Policy) String() string {
+	return p.Kind + " " + p.ID()
+}
+
+// RangeFor returns the range of the first rule matching a pod with the given labels, or the
+// catch-all range when no rule does.
+func (p *Policy) RangeFor(podLabels map[string]string) Range {
+	for _, rule := range p.Rules {
+		if rule.Selector == nil || rule.Selector.Matches(labels.Set(podLabels)) {
+			return rule.Range
+		}
+	}
+	return p.Range
+}
+
+// Applicable returns the policies in effect in a namespace with the given labels: the policies of
+// the namespace or, when it has none, the first cluster policy by name that selects the namespace.
+func Applicable(policies []Policy, namespace string, namespaceLabels map[string]string) []Policy {
+	var namespaced, cluster []Policy
+	for _, p := range policies {
+		switch p.Namespace {
+		case namespace:
+			namespaced = append(namespaced, p)
+		case "":
+			cluster = append(cluster, p)
+		}
+	}
+	if len(namespaced) > 0 {
+		return namespaced
+	}
+
+	sort.SliceStable(cluster, func(i, j int) bool { return cluster[i].Name < cluster[j].Name })
+	for _, p := range cluster {
+		if p.NamespaceSelector == nil || p.NamespaceSelector.Matches(labels.Set(namespaceLabels)) {
+			return []Policy{p}
+		}
+	}
+	return nil
+}
+
+// ForNetwork returns the policies bounding a network, given as namespace/name, each with the range
+// of the network as its catch-all range and without rules, so that Evaluate validates the bandwidth
+// of an interface attached to the network as it does the bandwidth of a pod.
+func ForNetwork(policies []Policy, network string) []Policy {
+	var bounding []Policy
+	for i := range policies {
+		for _, n := range policies[i].Networks {
+			if n.Network != network {
+				continue
+			}
+			p := policies[i]
+			p.Rules, p.Range, p.Networks = nil, n.Range, nil
+			bounding = append(bounding, p)
+		}
+	}
+	return bounding
+}
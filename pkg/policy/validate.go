@@ -0,0 +1,133 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+)
+
+var (
+	// MinBandwidth and MaxBandwidth are the smallest and the largest reasonable bandwidth, in bits.
+	MinBandwidth = resource.MustParse("1k")
+	MaxBandwidth = resource.MustParse("1P")
+)
+
+// bursts maps the rate annotations to the key of their token bucket.
+var bursts = []struct{ rate, burst string }{
+	{common.IngressBandwidthAnnotation, common.IngressBurstKey},
+	{common.EgressBandwidthAnnotation, common.EgressBurstKey},
+}
+
+// ValidateQuantity rejects a bandwidth that is unreasonably small or large.
+func ValidateQuantity(q resource.Quantity) error {
+	if q.Value() < MinBandwidth.Value() || q.Value() > MaxBandwidth.Value() {
+		return common.ErrInvalidBandwidthRange
+	}
+	return nil
+}
+
+// RangeError is an invalid bound of a range: the min, default or max of an annotation.
+type RangeError struct {
+	Key   string
+	Limit string
+	Value resource.Quantity
+	// Err is common.ErrInvalidBandwidthRange, common.ErrInvalidBurstRate or common.ErrInvalidBandwidthMaxMin.
+	Err    error
+	Detail string
+}
+
+func (e RangeError) Error() string {
+	return fmt.Sprintf("%s %s %s: %s", e.Limit, e.Key, e.Value.String(), e.Detail)
+}
+
+// ValidateRange reports every bound of the range that is unreasonably small or large, whose burst
+// cannot sustain its rate, or that is not within min <= default <= max. The bounds are checked
+// limit by limit, min first, then against each other.
+func ValidateRange(r Range) []RangeError {
+	var errs []RangeError
+	for _, limit := range []struct {
+		name string
+		get  func(Bound) resource.Quantity
+	}{
+		{"min", func(b Bound) resource.Quantity { return b.Min }},
+		{"default", func(b Bound) resource.Quantity { return b.Default }},
+		{"max", func(b Bound) resource.Quantity { return b.Max }},
+	} {
+		for _, key := range Keys {
+			q := limit.get(r.Get(key))
+			if q.IsZero() {
+				continue
+			}
+			if err := ValidateQuantity(q); err != nil {
+				errs = append(errs, RangeError{Key: key, Limit: limit.name, Value: q, Err: err, Detail: err.Error()})
+			}
+		}
+		// A token bucket smaller than one millisecond of traffic cannot sustain its rate.
+		for _, b := range bursts {
+			rate, burst := limit.get(r.Get(b.rate)), limit.get(r.Get(b.burst))
+			if !rate.IsZero() && !burst.IsZero() && burst.Value() < rate.Value()/1000 {
+				errs = append(errs, RangeError{Key: b.burst, Limit: limit.name, Value: burst,
+					Err: common.ErrInvalidBurstRate, Detail: common.ErrInvalidBurstRate.Error()})
+			}
+		}
+	}
+
+	for _, key := range Keys {
+		b := r.Get(key)
+		if !b.Min.IsZero() && !b.Max.IsZero() && b.Min.Value() > b.Max.Value() {
+			errs = append(errs, RangeError{Key: key, Limit: "min", Value: b.Min, Err: common.ErrInvalidBandwidthMaxMin,
+				Detail: fmt.Sprintf("must be less than or equal to max %s", b.Max.String())})
+		}
+		if !b.Min.IsZero() && !b.Default.IsZero() && b.Min.Value() > b.Default.Value() {
+			errs = append(errs, RangeError{Key: key, Limit: "default", Value: b.Default, Err: common.ErrInvalidBandwidthMaxMin,
+				Detail: fmt.Sprintf("must be greater than or equal to min %s", b.Min.String())})
+		}
+		if !b.Default.IsZero() && !b.Max.IsZero() && b.Default.Value() > b.Max.Value() {
+			errs = append(errs, RangeError{Key: key, Limit: "default", Value: b.Default, Err: common.ErrInvalidBandwidthMaxMin,
+				Detail: fmt.Sprintf("must be less than or equal to max %s", b.Max.String())})
+		}
+	}
+	return errs
+}
+
+// AnnotationPath returns the field path of a pod annotation.
+func AnnotationPath(key string) *field.Path {
+	return field.NewPath("metadata", "annotations").Key(key)
+}
+
+// ValidateAnnotations reports the bandwidth annotations whose value is not a quantity.
+func ValidateAnnotations(annotations map[string]string) field.ErrorList {
+	var errs field.ErrorList
+	for _, key := range PodKeys {
+		val, ok := annotations[key]
+		if !ok {
+			continue
+		}
+		q, err := resource.ParseQuantity(val)
+		if err != nil {
+			errs = append(errs, field.Invalid(AnnotationPath(key), val, "must be a quantity such as 100M or 1G"))
+		} else if q.Sign() < 0 {
+			errs = append(errs, field.Invalid(AnnotationPath(key), val, "must not be negative"))
+		}
+	}
+	return errs
+}
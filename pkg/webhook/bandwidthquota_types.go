@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BandwidthQuotaSpec defines the desired state of BandwidthQuota
+// +kubebuilder:validation:XValidation:rule="has(self.hard.ingress__dash__bandwidth) || has(self.hard.egress__dash__bandwidth)",message="at least one of hard.ingress-bandwidth or hard.egress-bandwidth must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.hard.ingress__dash__burst) && !has(self.hard.egress__dash__burst)",message="hard bounds the sum of the pod rates, bursts are not supported"
+type BandwidthQuotaSpec struct {
+	// Hard is the total ingress/egress bandwidth the pods of the namespace may claim. It does not
+	// accept bursts.
+	Hard CustomItems `json:"hard"`
+}
+
+// BandwidthItems is a total ingress/egress bandwidth. Unlike CustomItems, it is not range validated,
+// as the sum of the pod bandwidth may be out of the range of a single policy.
+type BandwidthItems struct {
+	// +optional
+	Ingress resource.Quantity `json:"ingress-bandwidth,omitzero"`
+	// +optional
+	Egress resource.Quantity `json:"egress-bandwidth,omitzero"`
+}
+
+// BandwidthQuotaStatus defines the observed state of BandwidthQuota
+type BandwidthQuotaStatus struct {
+	// Hard is the enforced hard limits.
+	Hard BandwidthItems `json:"hard,omitempty"`
+	// Used is the bandwidth currently claimed by the pods of the namespace.
+	Used BandwidthItems `json:"used,omitempty"`
+}
+
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=bwq
+// +kubebuilder:printcolumn:name="Ingress Hard",type=string,JSONPath=`.status.hard.ingress-bandwidth`
+// +kubebuilder:printcolumn:name="Ingress Used",type=string,JSONPath=`.status.used.ingress-bandwidth`
+// +kubebuilder:printcolumn:name="Egress Hard",type=string,JSONPath=`.status.hard.egress-bandwidth`
+// +kubebuilder:printcolumn:name="Egress Used",type=string,JSONPath=`.status.used.egress-bandwidth`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// BandwidthQuota is the Schema for the bandwidthquotas API.
+// It bounds the total bandwidth claimed by the pods of a namespace.
+//
+// The bound is best effort: admission sums the pods of the informer cache and reserves nothing, so
+// pods admitted concurrently, or before the cache observes each other, may together exceed Hard.
+// Status.Used then shows the overshoot, and later pods are rejected until the usage drops below Hard.
+type BandwidthQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BandwidthQuotaSpec   `json:"spec"`
+	Status BandwidthQuotaStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BandwidthQuotaList contains a list of BandwidthQuota
+type BandwidthQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BandwidthQuota `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BandwidthQuota{}, &BandwidthQuotaList{})
+}
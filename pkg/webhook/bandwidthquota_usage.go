@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+)
+
+// PodBandwidth returns the bandwidth claimed by the given pod annotations.
+// Missing or unparsable annotations claim nothing.
+func PodBandwidth(annotations map[string]string) CustomItems {
+	var items CustomItems
+	for _, item := range []struct {
+		key string
+		to  *resource.Quantity
+	}{
+		{common.IngressBandwidthAnnotation, &items.Ingress},
+		{common.EgressBandwidthAnnotation, &items.Egress},
+	} {
+		if q, err := resource.ParseQuantity(annotations[item.key]); err == nil {
+			*item.to = q
+		}
+	}
+	return items
+}
+
+// BandwidthUsage sums the bandwidth claimed by the pods, skipping the pod named exclude
+// and the pods that reached a terminal phase.
+func BandwidthUsage(pods []corev1.Pod, exclude string) CustomItems {
+	var used CustomItems
+	for i := range pods {
+		if exclude != "" && pods[i].Name == exclude {
+			continue
+		}
+		if pods[i].Status.Phase == corev1.PodSucceeded || pods[i].Status.Phase == corev1.PodFailed {
+			continue
+		}
+		b := PodBandwidth(pods[i].Annotations)
+		used.Ingress.Add(b.Ingress)
+		used.Egress.Add(b.Egress)
+	}
+	return used
+}
+
+// Exceeded returns a message for every hard limit of the quota that used plus requested goes over.
+func (q *BandwidthQuota) Exceeded(used, requested CustomItems) []string {
+	var exceeded []string
+	for _, d := range []struct {
+		key                   string
+		hard, used, requested resource.Quantity
+	}{
+		{common.IngressBandwidthAnnotation, q.Spec.Hard.Ingress, used.Ingress, requested.Ingress},
+		{common.EgressBandwidthAnnotation, q.Spec.Hard.Egress, used.Egress, requested.Egress},
+	} {
+		if d.hard.IsZero() || d.requested.IsZero() {
+			continue
+		}
+		total := d.used.DeepCopy()
+		total.Add(d.requested)
+		if total.Cmp(d.hard) > 0 {
+			exceeded = append(exceeded, fmt.Sprintf("%s: requested %s, used %s, limited %s",
+				d.key, d.requested.String(), d.used.String(), d.hard.String()))
+		}
+	}
+	return exceeded
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	wk "sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/policy"
+)
+
+func (r *BandwidthQuota) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&BandwidthQuota{}).
+		Complete()
+}
+
+var _ wk.CustomValidator = &BandwidthQuota{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *BandwidthQuota) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	q, ok := obj.(*BandwidthQuota)
+	if !ok {
+		return nil, fmt.Errorf("expected a BandwidthQuota but got a %T", obj)
+	}
+	customlimitrangelog.Info("validate bandwidthquota create", "name", q.Name, "request", q)
+	return nil, q.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *BandwidthQuota) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	q, ok := newObj.(*BandwidthQuota)
+	if !ok {
+		return nil, fmt.Errorf("expected a BandwidthQuota but got a %T", newObj)
+	}
+	customlimitrangelog.Info("validate bandwidthquota update", "name", q.Name, "request", q)
+	return nil, q.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *BandwidthQuota) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (r *BandwidthQuota) validate() error {
+	var allErrs field.ErrorList
+	if r.Spec.Hard.Ingress.IsZero() && r.Spec.Hard.Egress.IsZero() {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec").Child("hard"),
+			"at least one of ingress-bandwidth or egress-bandwidth must be set"))
+	}
+	for _, f := range itemFields {
+		q := *f.get(&r.Spec.Hard)
+		if q.IsZero() {
+			continue
+		}
+		if !slices.Contains(policy.PodKeys, f.key) {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "hard", f.name),
+				"hard bounds the sum of the pod rates, bursts are not supported"))
+			continue
+		}
+		if err := policy.ValidateQuantity(q); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "hard", f.name), q.String(), err.Error()))
+		}
+	}
+	customlimitrangelog.Info("validate bandwidthquota", "field.ErrorList", allErrs)
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return errors.NewInvalid(GroupVersion.WithKind("BandwidthQuota").GroupKind(), r.Name, allErrs)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+)
+
+func TestBandwidthQuotaValidate(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	v := &BandwidthQuota{}
+	ctx := context.Background()
+
+	q := &BandwidthQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "test-a"},
+		Spec:       BandwidthQuotaSpec{Hard: CustomItems{Ingress: resource.MustParse("10G")}},
+	}
+	_, err := v.ValidateCreate(ctx, q)
+	assert.Nil(err)
+	_, err = v.ValidateUpdate(ctx, q, q)
+	assert.Nil(err)
+	_, err = v.ValidateDelete(ctx, q)
+	assert.Nil(err)
+
+	_, err = v.ValidateCreate(ctx, &BandwidthQuota{})
+	assert.NotNil(err)
+
+	bad := q.DeepCopy()
+	bad.Spec.Hard.Egress = resource.MustParse("10")
+	_, err = v.ValidateUpdate(ctx, q, bad)
+	assert.NotNil(err)
+
+	// the quota bounds rates only
+	bad = q.DeepCopy()
+	bad.Spec.Hard.IngressBurst = resource.MustParse("10M")
+	_, err = v.ValidateCreate(ctx, bad)
+	assert.ErrorContains(err, "spec.hard.ingress-burst: Forbidden")
+
+	_, err = v.ValidateCreate(ctx, &ClusterCustomLimitRange{})
+	assert.NotNil(err)
+}
+
+func TestBandwidthUsage(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	pod := func(name, ingress string, phase corev1.PodPhase) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{common.IngressBandwidthAnnotation: ingress}},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	pods := []corev1.Pod{
+		pod("a", "100M", corev1.PodRunning),
+		pod("b", "200M", corev1.PodPending),
+		pod("c", "1G", corev1.PodFailed),
+		pod("d", "bogus", corev1.PodRunning),
+	}
+
+	used := BandwidthUsage(pods, "")
+	assert.Equal("300M", used.Ingress.String())
+	assert.True(used.Egress.IsZero())
+	used = BandwidthUsage(pods, "b")
+	assert.Equal("100M", used.Ingress.String())
+
+	q := &BandwidthQuota{Spec: BandwidthQuotaSpec{Hard: CustomItems{Ingress: resource.MustParse("500M")}}}
+	assert.Empty(q.Exceeded(BandwidthUsage(pods, ""), CustomItems{Ingress: resource.MustParse("200M")}))
+	assert.Len(q.Exceeded(BandwidthUsage(pods, ""), CustomItems{Ingress: resource.MustParse("300M")}), 1)
+	// egress is not limited by the quota
+	assert.Empty(q.Exceeded(BandwidthUsage(pods, ""), CustomItems{Egress: resource.MustParse("10G")}))
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterCustomLimitRangeSpec defines the desired state of ClusterCustomLimitRange
+type ClusterCustomLimitRangeSpec struct {
+	// NamespaceSelector selects the namespaces the policy applies to.
+	// A nil selector selects every namespace.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	LRange            LimitRange            `json:"limitrange"`
+	// EnforcementMode is one of enforce (default), clamp, warn, audit or dryRun.
+	EnforcementMode EnforcementMode `json:"enforcementMode,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=cclr
+// +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.spec.enforcementMode`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ClusterCustomLimitRange is the Schema for the clustercustomlimitranges API.
+// It applies to namespaces that have no CustomLimitRange of their own.
+type ClusterCustomLimitRange struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClusterCustomLimitRangeSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterCustomLimitRangeList contains a list of ClusterCustomLimitRange
+type ClusterCustomLimitRangeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterCustomLimitRange `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterCustomLimitRange{}, &ClusterCustomLimitRangeList{})
+}
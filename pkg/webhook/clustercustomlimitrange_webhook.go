@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	wk "sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/policy"
+)
+
+func (r *ClusterCustomLimitRange) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&ClusterCustomLimitRange{}).
+		WithDefaulter(&ClusterCustomLimitRange{}).
+		Complete()
+}
+
+var _ wk.CustomDefaulter = &ClusterCustomLimitRange{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type. On update it
+// drops the bursts of the pod network stored before they were forbidden.
+func (r *ClusterCustomLimitRange) Default(ctx context.Context, obj runtime.Object) error {
+	c, ok := obj.(*ClusterCustomLimitRange)
+	if !ok {
+		return fmt.Errorf("expected a ClusterCustomLimitRange but got a %T", obj)
+	}
+	if req, err := admission.RequestFromContext(ctx); err == nil && req.Operation == admissionv1.Update {
+		if stripPodBursts(&c.Spec.LRange.BandwidthRange) {
+			customlimitrangelog.Info("drop the bursts of the pod network", "name", c.Name)
+		}
+	}
+	return nil
+}
+
+var _ wk.CustomValidator = &ClusterCustomLimitRange{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ClusterCustomLimitRange) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	c, ok := obj.(*ClusterCustomLimitRange)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterCustomLimitRange but got a %T", obj)
+	}
+	customlimitrangelog.Info("validate cluster create", "name", c.Name, "request", c)
+	return nil, c.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ClusterCustomLimitRange) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	c, ok := newObj.(*ClusterCustomLimitRange)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterCustomLimitRange but got a %T", newObj)
+	}
+	customlimitrangelog.Info("validate cluster update", "name", c.Name, "request", c)
+	return nil, c.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ClusterCustomLimitRange) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (r *ClusterCustomLimitRange) validate() error {
+	var allErrs field.ErrorList
+	if r.Spec.NamespaceSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(r.Spec.NamespaceSelector); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("namespaceSelector"),
+				r.Spec.NamespaceSelector,
+				err.Error()))
+		}
+	}
+	if err := validateEnforcementMode(r.Spec.EnforcementMode); err != nil {
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("spec").Child("enforcementMode"),
+			r.Spec.EnforcementMode, enforcementModes))
+	}
+	lr := r.Spec.LRange
+	allErrs = append(allErrs, validateItems(lr.Min, lr.Default, lr.Max, field.NewPath("spec", "limitrange"))...)
+	allErrs = append(allErrs, validatePodBursts(lr.BandwidthRange, field.NewPath("spec", "limitrange"))...)
+	customlimitrangelog.Info("validate cluster fields", "field.ErrorList", allErrs)
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return errors.NewInvalid(GroupVersion.WithKind("ClusterCustomLimitRange").GroupKind(), r.Name, allErrs)
+}
+
+// Policy returns the ClusterCustomLimitRange as a cluster policy of the evaluation engine.
+// An invalid namespaceSelector matches no namespace.
+func (r *ClusterCustomLimitRange) Policy() policy.Policy {
+	p := policy.Policy{
+		Kind:       "ClusterCustomLimitRange",
+		Name:       r.Name,
+		Generation: r.Generation,
+		Mode:       policy.Mode(r.Spec.EnforcementMode),
+		Range:      r.Spec.LRange.PodBounds(),
+	}
+	if r.Spec.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(r.Spec.NamespaceSelector)
+		if err != nil {
+			selector = labels.Nothing()
+		}
+		p.NamespaceSelector = selector
+	}
+	return p
+}
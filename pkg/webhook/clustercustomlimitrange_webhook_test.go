@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestClusterCustomLimitRangeValidate(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	v := &ClusterCustomLimitRange{}
+	ctx := context.Background()
+
+	c := &ClusterCustomLimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant"},
+		Spec: ClusterCustomLimitRangeSpec{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "true"}},
+			LRange: LimitRange{
+				BandwidthRange: BandwidthRange{
+					Max:     CustomItems{Ingress: resource.MustParse("1G")},
+					Min:     CustomItems{Ingress: resource.MustParse("10M")},
+					Default: CustomItems{Ingress: resource.MustParse("100M")},
+				},
+			},
+		},
+	}
+	_, err := v.ValidateCreate(ctx, c)
+	assert.Nil(err)
+	_, err = v.ValidateUpdate(ctx, c, c)
+	assert.Nil(err)
+	_, err = v.ValidateDelete(ctx, c)
+	assert.Nil(err)
+
+	bad := c.DeepCopy()
+	bad.Spec.LRange.Default.Ingress = resource.MustParse("10G")
+	_, err = v.ValidateCreate(ctx, bad)
+	assert.NotNil(err)
+	_, err = v.ValidateUpdate(ctx, c, bad)
+	assert.NotNil(err)
+
+	bad = c.DeepCopy()
+	bad.Spec.NamespaceSelector = &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tenant", Operator: "Bogus"}},
+	}
+	_, err = v.ValidateCreate(ctx, bad)
+	assert.NotNil(err)
+
+	_, err = v.ValidateCreate(ctx, &CustomLimitRange{})
+	assert.NotNil(err)
+}
+
+func TestClusterCustomLimitRangeDefault(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	c := &ClusterCustomLimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "tenant"},
+		Spec: ClusterCustomLimitRangeSpec{
+			LRange: LimitRange{
+				BandwidthRange: BandwidthRange{
+					Max: CustomItems{Ingress: resource.MustParse("1G"), IngressBurst: resource.MustParse("10M")},
+				},
+			},
+		},
+	}
+
+	// a create keeps the burst, so that the validation rejects it
+	assert.Nil(c.Default(context.Background(), c))
+	assert.Equal("10M", c.Spec.LRange.Max.IngressBurst.String())
+
+	ctx := admission.NewContextWithRequest(context.Background(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{Operation: admissionv1.Update},
+	})
+	assert.Nil(c.Default(ctx, c))
+	assert.True(c.Spec.LRange.Max.IngressBurst.IsZero())
+	assert.Equal("1G", c.Spec.LRange.Max.Ingress.String())
+	_, err := c.ValidateUpdate(ctx, c, c)
+	assert.Nil(err)
+}
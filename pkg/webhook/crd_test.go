@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const crdDir = "../../hack/deployment/crds"
+
+func loadCRD(t *testing.T, name string) *apiextensionsv1.CustomResourceDefinition {
+	data, err := os.ReadFile(filepath.Join(crdDir, name))
+	assert.Nil(t, err)
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	assert.Nil(t, yaml.Unmarshal(data, crd))
+	assert.NotEmpty(t, crd.Spec.Versions)
+	return crd
+}
+
+func crdSchema(crd *apiextensionsv1.CustomResourceDefinition, version string) apiextensionsv1.JSONSchemaProps {
+	for _, v := range crd.Spec.Versions {
+		if v.Name == version {
+			return *v.Schema.OpenAPIV3Schema
+		}
+	}
+	return apiextensionsv1.JSONSchemaProps{}
+}
+
+// quantityProps collects the int-or-string properties under schema, keyed by their path.
+func quantityProps(path string, schema apiextensionsv1.JSONSchemaProps, props map[string]apiextensionsv1.JSONSchemaProps) {
+	if schema.XIntOrString {
+		props[path] = schema
+	}
+	for name, p := range schema.Properties {
+		quantityProps(path+"."+name, p, props)
+	}
+	if schema.Items != nil && schema.Items.Schema != nil {
+		quantityProps(path+"[*]", *schema.Items.Schema, props)
+	}
+}
+
+func TestCRDValidationRules(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	for _, name := range []string{
+		"custom.cmss.com_customlimitranges.yaml",
+		"custom.cmss.com_clustercustomlimitranges.yaml",
+		"custom.cmss.com_bandwidthquotas.yaml",
+	} {
+		for _, version := range loadCRD(t, name).Spec.Versions {
+			schema := version.Schema.OpenAPIV3Schema
+
+			// every spec quantity is bounded, so that the cost of its rules can be estimated, and range checked
+			props := map[string]apiextensionsv1.JSONSchemaProps{}
+			quantityProps("spec", schema.Properties["spec"], props)
+			assert.NotEmpty(props, name)
+			for path, p := range props {
+				assert.NotNil(p.MaxLength, "%s %s %s", name, version.Name, path)
+				assert.Len(p.XValidations, 1, "%s %s %s", name, version.Name, path)
+			}
+
+			// status quantities are sums, not policy values
+			props = map[string]apiextensionsv1.JSONSchemaProps{}
+			quantityProps("status", schema.Properties["status"], props)
+			for path, p := range props {
+				assert.Empty(p.XValidations, "%s %s %s", name, version.Name, path)
+			}
+		}
+	}
+
+	crd := loadCRD(t, "custom.cmss.com_customlimitranges.yaml")
+	spec := crdSchema(crd, "v1").Properties["spec"]
+	lr := spec.Properties["limitrange"]
+	// one rule per range ordering, and one forbidding the bursts of the pod network
+	assert.Len(lr.XValidations, 3*len(itemFields)+1)
+	assert.Len(lr.Properties["max"].XValidations, 2)
+	assert.Equal([]apiextensionsv1.JSON{{Raw: []byte(`"pod"`)}, {Raw: []byte(`"Pod"`)}, {Raw: []byte(`"POD"`)}},
+		lr.Properties["type"].Enum)
+	rules := spec.Properties["rules"]
+	assert.NotNil(rules.MaxItems)
+	assert.Len(rules.Items.Schema.XValidations, 3*len(itemFields)+1)
+
+	// v2 is the storage version, with the same rules on its flattened spec
+	for _, v := range crd.Spec.Versions {
+		assert.Equal(v.Name == "v2", v.Storage, v.Name)
+		assert.True(v.Served, v.Name)
+	}
+	spec = crdSchema(crd, "v2").Properties["spec"]
+	assert.Len(spec.XValidations, 3*len(itemFields)+1)
+	assert.Len(spec.Properties["max"].Properties["ingress"].XValidations, 1)
+	assert.Len(spec.Properties["rules"].Items.Schema.XValidations, 3*len(itemFields)+1)
+	assert.Len(spec.Properties["networks"].Items.Schema.XValidations, 3*len(itemFields))
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	v2 "github.com/kubeservice-stack/custom-limit-range/pkg/webhook/v2"
+)
+
+var _ conversion.Convertible = &CustomLimitRange{}
+
+// ConvertTo converts this CustomLimitRange to the v2 hub version.
+// v2 has no LimitRange type: a type other than the canonical Pod is kept in the
+// LimitRangeTypeAnnotation so that the object converts back unchanged.
+func (r *CustomLimitRange) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v2.CustomLimitRange)
+	if !ok {
+		return fmt.Errorf("expected a v2 CustomLimitRange but got a %T", dstRaw)
+	}
+
+	dst.ObjectMeta = *r.ObjectMeta.DeepCopy()
+	if r.Spec.LRange.Type != LimitRangeTypePod {
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[common.LimitRangeTypeAnnotation] = r.Spec.LRange.Type
+	}
+
+	dst.Spec = v2.CustomLimitRangeSpec{
+		BandwidthRange:  rangeToV2(r.Spec.LRange.BandwidthRange),
+		EnforcementMode: v2.EnforcementMode(r.Spec.EnforcementMode),
+		Priority:        r.Spec.Priority,
+		DefaultPolicy:   v2.DefaultPolicy(r.Spec.DefaultPolicy),
+	}
+	for _, rule := range r.Spec.Rules {
+		dst.Spec.Rules = append(dst.Spec.Rules, v2.LimitRangeRule{
+			Name:           rule.Name,
+			PodSelector:    rule.PodSelector.DeepCopy(),
+			BandwidthRange: rangeToV2(rule.BandwidthRange),
+		})
+	}
+	for _, network := range r.Spec.Networks {
+		dst.Spec.Networks = append(dst.Spec.Networks, v2.NetworkLimitRange{
+			Name:           network.Name,
+			BandwidthRange: rangeToV2(network.BandwidthRange),
+		})
+	}
+
+	dst.Status = v2.CustomLimitRangeStatus{
+		ObservedGeneration: r.Status.ObservedGeneration,
+		CompliantPods:      r.Status.CompliantPods,
+		DefaultedPods:      r.Status.DefaultedPods,
+		OutOfRangePods:     r.Status.OutOfRangePods,
+		LastEvaluationTime: r.Status.LastEvaluationTime.DeepCopy(),
+	}
+	for _, c := range r.Status.Conditions {
+		dst.Status.Conditions = append(dst.Status.Conditions, *c.DeepCopy())
+	}
+	return nil
+}
+
+// ConvertFrom converts from the v2 hub version to this version.
+func (r *CustomLimitRange) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v2.CustomLimitRange)
+	if !ok {
+		return fmt.Errorf("expected a v2 CustomLimitRange but got a %T", srcRaw)
+	}
+
+	r.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	lrType := LimitRangeTypePod
+	if t, ok := r.Annotations[common.LimitRangeTypeAnnotation]; ok {
+		lrType = t
+		delete(r.Annotations, common.LimitRangeTypeAnnotation)
+	}
+
+	r.Spec = CustomLimitRangeSpec{
+		LRange: LimitRange{
+			Type:           lrType,
+			BandwidthRange: rangeFromV2(src.Spec.BandwidthRange),
+		},
+		EnforcementMode: EnforcementMode(src.Spec.EnforcementMode),
+		Priority:        src.Spec.Priority,
+		DefaultPolicy:   DefaultPolicy(src.Spec.DefaultPolicy),
+	}
+	for _, rule := range src.Spec.Rules {
+		r.Spec.Rules = append(r.Spec.Rules, LimitRangeRule{
+			Name:           rule.Name,
+			PodSelector:    rule.PodSelector.DeepCopy(),
+			BandwidthRange: rangeFromV2(rule.BandwidthRange),
+		})
+	}
+	for _, network := range src.Spec.Networks {
+		r.Spec.Networks = append(r.Spec.Networks, NetworkLimitRange{
+			Name:           network.Name,
+			BandwidthRange: rangeFromV2(network.BandwidthRange),
+		})
+	}
+
+	r.Status = CustomLimitRangeStatus{
+		ObservedGeneration: src.Status.ObservedGeneration,
+		CompliantPods:      src.Status.CompliantPods,
+		DefaultedPods:      src.Status.DefaultedPods,
+		OutOfRangePods:     src.Status.OutOfRangePods,
+		LastEvaluationTime: src.Status.LastEvaluationTime.DeepCopy(),
+	}
+	for _, c := range src.Status.Conditions {
+		r.Status.Conditions = append(r.Status.Conditions, *c.DeepCopy())
+	}
+	return nil
+}
+
+func rangeToV2(r BandwidthRange) v2.BandwidthRange {
+	return v2.BandwidthRange{Max: limitsToV2(r.Max), Min: limitsToV2(r.Min), Default: limitsToV2(r.Default)}
+}
+
+func rangeFromV2(r v2.BandwidthRange) BandwidthRange {
+	return BandwidthRange{Max: limitsFromV2(r.Max), Min: limitsFromV2(r.Min), Default: limitsFromV2(r.Default)}
+}
+
+func limitsToV2(item CustomItems) v2.Limits {
+	return v2.Limits{
+		Ingress: v2.Bandwidth{Rate: item.Ingress.DeepCopy(), Burst: item.IngressBurst.DeepCopy()},
+		Egress:  v2.Bandwidth{Rate: item.Egress.DeepCopy(), Burst: item.EgressBurst.DeepCopy()},
+	}
+}
+
+func limitsFromV2(limits v2.Limits) CustomItems {
+	return CustomItems{
+		Ingress:      limits.Ingress.Rate.DeepCopy(),
+		Egress:       limits.Egress.Rate.DeepCopy(),
+		IngressBurst: limits.Ingress.Burst.DeepCopy(),
+		EgressBurst:  limits.Egress.Burst.DeepCopy(),
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+	"sigs.k8s.io/randfill"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	v2 "github.com/kubeservice-stack/custom-limit-range/pkg/webhook/v2"
+)
+
+// conversionFiller fills API objects with random but serializable values: quantities are
+// unset or canonical decimal values and times have a second precision, as after a round trip
+// through the apiserver.
+func conversionFiller() *randfill.Filler {
+	return randfill.New().NilChance(0.2).NumElements(0, 3).Funcs(
+		func(q *resource.Quantity, c randfill.Continue) {
+			if c.Intn(3) == 0 {
+				*q = resource.Quantity{}
+				return
+			}
+			*q = *resource.NewQuantity(c.Int63n(1e15), resource.DecimalSI)
+		},
+		func(t *metav1.Time, c randfill.Continue) {
+			*t = metav1.Unix(c.Int63n(1e9), 0)
+		},
+		func(lr *LimitRange, c randfill.Continue) {
+			c.FillNoCustom(lr)
+			lr.Type = []string{"pod", "Pod", "POD", ""}[c.Intn(4)]
+		},
+	)
+}
+
+func TestCustomLimitRangeConversionRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	f := conversionFiller()
+	for i := 0; i < 1000; i++ {
+		original := &CustomLimitRange{}
+		f.Fill(original)
+		original.TypeMeta = metav1.TypeMeta{}
+
+		hub := &v2.CustomLimitRange{}
+		assert.Nil(original.DeepCopy().ConvertTo(hub))
+		got := &CustomLimitRange{}
+		assert.Nil(got.ConvertFrom(hub))
+		if !assert.True(equality.Semantic.DeepEqual(original, got), "v1 -> v2 -> v1") {
+			t.Logf("original: %#v\ngot: %#v", original, got)
+			return
+		}
+
+		hubOriginal := &v2.CustomLimitRange{}
+		f.Fill(hubOriginal)
+		hubOriginal.TypeMeta = metav1.TypeMeta{}
+		spoke := &CustomLimitRange{}
+		assert.Nil(spoke.ConvertFrom(hubOriginal.DeepCopy()))
+		hub = &v2.CustomLimitRange{}
+		assert.Nil(spoke.ConvertTo(hub))
+		if !assert.True(equality.Semantic.DeepEqual(hubOriginal, hub), "v2 -> v1 -> v2") {
+			t.Logf("original: %#v\ngot: %#v", hubOriginal, hub)
+			return
+		}
+	}
+}
+
+func TestCustomLimitRangeConversion(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	assert.Nil(AddToScheme(scheme))
+	assert.Nil(v2.AddToScheme(scheme))
+	ok, err := conversion.IsConvertible(scheme, &CustomLimitRange{})
+	assert.Nil(err)
+	assert.True(ok)
+
+	r := &CustomLimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-a"},
+		Spec: CustomLimitRangeSpec{
+			LRange: LimitRange{
+				Type: "pod",
+				BandwidthRange: BandwidthRange{
+					Max: CustomItems{Ingress: resource.MustParse("1G"), IngressBurst: resource.MustParse("10M")},
+				},
+			},
+			Rules: []LimitRangeRule{{Name: "db", BandwidthRange: BandwidthRange{Min: CustomItems{Egress: resource.MustParse("100M")}}}},
+		},
+	}
+	hub := &v2.CustomLimitRange{}
+	assert.Nil(r.ConvertTo(hub))
+	assert.Equal("1G", hub.Spec.Max.Ingress.Rate.String())
+	assert.Equal("10M", hub.Spec.Max.Ingress.Burst.String())
+	assert.Equal("100M", hub.Spec.Rules[0].Min.Egress.Rate.String())
+	assert.Equal("pod", hub.Annotations[common.LimitRangeTypeAnnotation])
+
+	// the canonical type needs no annotation
+	r.Spec.LRange.Type = LimitRangeTypePod
+	hub = &v2.CustomLimitRange{}
+	assert.Nil(r.ConvertTo(hub))
+	assert.NotContains(hub.Annotations, common.LimitRangeTypeAnnotation)
+
+	got := &CustomLimitRange{}
+	assert.Nil(got.ConvertFrom(hub))
+	assert.Equal(LimitRangeTypePod, got.Spec.LRange.Type)
+}
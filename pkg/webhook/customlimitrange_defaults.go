@@ -0,0 +1,170 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+)
+
+var defaultPolicies = []string{
+	string(DefaultPolicyNone),
+	string(DefaultPolicyMin),
+	string(DefaultPolicyMax),
+}
+
+func validateDefaultPolicy(policy DefaultPolicy) error {
+	switch policy {
+	case "", DefaultPolicyNone, DefaultPolicyMin, DefaultPolicyMax:
+		return nil
+	}
+	return common.ErrInvalidDefaultPolicy
+}
+
+// rangeRef points at the bounds of the catch-all range, of a rule or of a network.
+type rangeRef struct {
+	path          *field.Path
+	min, def, max *CustomItems
+}
+
+func (r *CustomLimitRange) ranges() []rangeRef {
+	refs := []rangeRef{{field.NewPath("spec", "limitrange"), &r.Spec.LRange.Min, &r.Spec.LRange.Default, &r.Spec.LRange.Max}}
+	for i := range r.Spec.Rules {
+		rule := &r.Spec.Rules[i]
+		refs = append(refs, rangeRef{field.NewPath("spec", "rules").Index(i), &rule.Min, &rule.Default, &rule.Max})
+	}
+	for i := range r.Spec.Networks {
+		network := &r.Spec.Networks[i]
+		refs = append(refs, rangeRef{field.NewPath("spec", "networks").Index(i), &network.Min, &network.Default, &network.Max})
+	}
+	return refs
+}
+
+// defaultFields maps the path of every default quantity to the quantity.
+func (r *CustomLimitRange) defaultFields() map[string]*resource.Quantity {
+	fields := map[string]*resource.Quantity{}
+	for _, ref := range r.ranges() {
+		for _, f := range itemFields {
+			fields[ref.path.Child("default", f.name).String()] = f.get(ref.def)
+		}
+	}
+	return fields
+}
+
+// applyDefaults canonicalizes the type and the quantities of r, then fills its unset defaults
+// according to its defaultPolicy and records them in the defaulted annotation.
+// On update, old is the stored object: a default it recorded that is left unchanged is derived
+// again, so that it follows its min or max, while a changed one is kept as an explicit value,
+// and the bursts of the pod network stored before they were forbidden are dropped.
+func (r *CustomLimitRange) applyDefaults(old *CustomLimitRange) {
+	if r.Spec.LRange.Type == "" || strings.EqualFold(r.Spec.LRange.Type, LimitRangeTypePod) {
+		r.Spec.LRange.Type = LimitRangeTypePod
+	}
+
+	if old != nil {
+		stripped := stripPodBursts(&r.Spec.LRange.BandwidthRange)
+		for i := range r.Spec.Rules {
+			stripped = stripPodBursts(&r.Spec.Rules[i].BandwidthRange) || stripped
+		}
+		if stripped {
+			customlimitrangelog.Info("drop the bursts of the pod network", "namespace", r.Namespace, "name", r.Name)
+		}
+
+		oldFields := old.defaultFields()
+		fields := r.defaultFields()
+		for _, path := range defaultedPaths(old.Annotations) {
+			q, ok := fields[path]
+			if oq, found := oldFields[path]; ok && found && q.Cmp(*oq) == 0 {
+				*q = resource.Quantity{}
+			}
+		}
+	}
+
+	var defaulted []string
+	for _, ref := range r.ranges() {
+		for _, items := range []*CustomItems{ref.min, ref.def, ref.max} {
+			normalizeItems(items)
+		}
+		for _, f := range itemFields {
+			def := f.get(ref.def)
+			if !def.IsZero() {
+				continue
+			}
+			var from *resource.Quantity
+			switch r.Spec.DefaultPolicy {
+			case DefaultPolicyMin:
+				from = f.get(ref.min)
+			case DefaultPolicyMax:
+				from = f.get(ref.max)
+			}
+			if from == nil || from.IsZero() {
+				continue
+			}
+			*def = from.DeepCopy()
+			defaulted = append(defaulted, ref.path.Child("default", f.name).String())
+		}
+	}
+
+	if len(defaulted) == 0 {
+		delete(r.Annotations, common.DefaultedAnnotation)
+		return
+	}
+	if r.Annotations == nil {
+		r.Annotations = map[string]string{}
+	}
+	sort.Strings(defaulted)
+	r.Annotations[common.DefaultedAnnotation] = strings.Join(defaulted, ",")
+}
+
+// stripPodBursts drops the bursts of a range of the pod network, which the validation forbids, and
+// reports whether there were any.
+func stripPodBursts(r *BandwidthRange) bool {
+	stripped := false
+	for _, items := range []*CustomItems{&r.Min, &r.Default, &r.Max} {
+		for _, q := range []*resource.Quantity{&items.IngressBurst, &items.EgressBurst} {
+			if !q.IsZero() {
+				*q = resource.Quantity{}
+				stripped = true
+			}
+		}
+	}
+	return stripped
+}
+
+func defaultedPaths(annotations map[string]string) []string {
+	val := annotations[common.DefaultedAnnotation]
+	if val == "" {
+		return nil
+	}
+	return strings.Split(val, ",")
+}
+
+// normalizeItems rewrites the set quantities in their canonical decimal form, e.g. 1000M as 1G.
+func normalizeItems(items *CustomItems) {
+	for _, f := range itemFields {
+		q := f.get(items)
+		if q.IsZero() {
+			continue
+		}
+		*q = *resource.NewQuantity(q.Value(), resource.DecimalSI)
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/policy"
+)
+
+// maxImpactPods is the number of pod names listed in an impact warning.
+const maxImpactPods = 5
+
+// impactWarnings describes the existing pods of the namespace affected when the CustomLimitRange
+// old is replaced by r, or deleted when r is nil: the pods whose bandwidth annotations are within
+// the current effective range but would fall outside the new one, and the pods carrying a default
+// of old that r no longer sets. items are the CustomLimitRanges of the namespace, or listErr the
+// error listing them.
+func (v *CustomLimitRangeValidator) impactWarnings(ctx context.Context, old, r *CustomLimitRange, items []CustomLimitRange, listErr error) admission.Warnings {
+	if v.Client == nil {
+		return nil
+	}
+	if listErr != nil {
+		return admission.Warnings{fmt.Sprintf("unable to check the pods affected in namespace %s: %v", old.Namespace, listErr)}
+	}
+	pods := &corev1.PodList{}
+	if err := v.Client.List(ctx, pods, client.InNamespace(old.Namespace)); err != nil {
+		customlimitrangelog.Info("list Pod error", "namespace", old.Namespace, "err", err)
+		return admission.Warnings{fmt.Sprintf("unable to check the pods affected in namespace %s: %v", old.Namespace, err)}
+	}
+
+	before := []CustomLimitRange{*old}
+	var after []CustomLimitRange
+	if r != nil {
+		after = append(after, *r)
+	}
+	for _, item := range items {
+		if item.Name == old.Name {
+			continue
+		}
+		before = append(before, item)
+		after = append(after, item)
+	}
+
+	oldPolicy := old.Policy()
+	var newPolicy policy.Policy
+	if r != nil {
+		newPolicy = r.Policy()
+	}
+	var outOfRange, defaulted []string
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if policy.Disabled(pod.Annotations) {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if len(after) > 0 && !policy.Merge(Policies(before), pod.Labels).Range.OutOfRange(pod.Annotations) &&
+			policy.Merge(Policies(after), pod.Labels).Range.OutOfRange(pod.Annotations) {
+			outOfRange = append(outOfRange, pod.Name)
+		}
+		if oldPolicy.RangeFor(pod.Labels).Defaulted(pod.Annotations) && (r == nil || !newPolicy.RangeFor(pod.Labels).Defaulted(pod.Annotations)) {
+			defaulted = append(defaulted, pod.Name)
+		}
+	}
+
+	action := "updated"
+	if r == nil {
+		action = "deleted"
+	}
+	var warnings admission.Warnings
+	if len(outOfRange) > 0 {
+		warnings = append(warnings, fmt.Sprintf("pods that would fall outside of the effective range once CustomLimitRange %s/%s is %s (%d): %s",
+			old.Namespace, old.Name, action, len(outOfRange), podNames(outOfRange)))
+	}
+	if len(defaulted) > 0 {
+		warnings = append(warnings, fmt.Sprintf("pods defaulted by CustomLimitRange %s/%s to a value it no longer sets once it is %s (%d): %s",
+			old.Namespace, old.Name, action, len(defaulted), podNames(defaulted)))
+	}
+	return warnings
+}
+
+// podNames joins the first maxImpactPods names.
+func podNames(names []string) string {
+	if len(names) <= maxImpactPods {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:maxImpactPods], ", "), len(names)-maxImpactPods)
+}
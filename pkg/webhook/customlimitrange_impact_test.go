@@ -0,0 +1,103 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+)
+
+func TestCustomLimitRangeImpactWarnings(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = AddToScheme(scheme)
+
+	clr := newMergeItem("a", 0,
+		CustomItems{Egress: resource.MustParse("1G")}, CustomItems{},
+		CustomItems{Egress: resource.MustParse("500M")})
+	objs := []client.Object{&clr}
+	for i := 0; i < 7; i++ {
+		objs = append(objs, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("big-%d", i), Namespace: "test-a",
+			Annotations: map[string]string{common.EgressBandwidthAnnotation: "800M"},
+		}})
+	}
+	objs = append(objs,
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: "defaulted", Namespace: "test-a",
+			Annotations: map[string]string{common.EgressBandwidthAnnotation: "500M"},
+		}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: "disabled", Namespace: "test-a",
+			Annotations: map[string]string{common.EgressBandwidthAnnotation: "800M", common.WebhookPodDisable: "disable"},
+		}},
+	)
+	lists := map[string]int{}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				lists[fmt.Sprintf("%T", list)]++
+				return c.List(ctx, list, opts...)
+			},
+		}).
+		Build()
+	v := &CustomLimitRangeValidator{Client: c}
+	ctx := context.Background()
+
+	// unchanged range: no impact, and no list
+	warnings, err := v.ValidateUpdate(ctx, &clr, &clr)
+	assert.Nil(err)
+	assert.Empty(warnings)
+	assert.Empty(lists)
+
+	tightened := clr.DeepCopy()
+	tightened.Spec.LRange.Max.Egress = resource.MustParse("600M")
+	tightened.Spec.LRange.Default.Egress = resource.MustParse("300M")
+	warnings, err = v.ValidateUpdate(ctx, &clr, tightened)
+	assert.Nil(err)
+	assert.Equal([]string{
+		"pods that would fall outside of the effective range once CustomLimitRange test-a/a is updated (7): big-0, big-1, big-2, big-3, big-4 and 2 more",
+		"pods defaulted by CustomLimitRange test-a/a to a value it no longer sets once it is updated (1): defaulted",
+	}, []string(warnings))
+	// the overlap and impact warnings share one list of the CustomLimitRanges
+	assert.Equal(map[string]int{"*webhook.CustomLimitRangeList": 1, "*v1.PodList": 1}, lists)
+
+	warnings, err = v.ValidateDelete(ctx, &clr)
+	assert.Nil(err)
+	assert.Equal([]string{
+		"pods defaulted by CustomLimitRange test-a/a to a value it no longer sets once it is deleted (1): defaulted",
+	}, []string(warnings))
+
+	_, err = v.ValidateDelete(ctx, &ClusterCustomLimitRange{})
+	assert.NotNil(err)
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/policy"
+)
+
+// itemFields lists the keys of the bounds a CustomItems covers, with their field names: the ingress and egress
+// rates, then the ingress and egress bursts. They are in the order of policy.Keys.
+var itemFields = []struct {
+	key  string
+	name string
+	get  func(*CustomItems) *resource.Quantity
+}{
+	{common.IngressBandwidthAnnotation, "ingress-bandwidth", func(c *CustomItems) *resource.Quantity { return &c.Ingress }},
+	{common.EgressBandwidthAnnotation, "egress-bandwidth", func(c *CustomItems) *resource.Quantity { return &c.Egress }},
+	{common.IngressBurstKey, "ingress-burst", func(c *CustomItems) *resource.Quantity { return &c.IngressBurst }},
+	{common.EgressBurstKey, "egress-burst", func(c *CustomItems) *resource.Quantity { return &c.EgressBurst }},
+}
+
+// Bounds returns the range of every bound the BandwidthRange covers, in the order of policy.Keys.
+func (lr BandwidthRange) Bounds() policy.Range {
+	bounds := make(policy.Range, 0, len(itemFields))
+	for _, f := range itemFields {
+		bounds = append(bounds, policy.Bound{Key: f.key, Min: *f.get(&lr.Min), Default: *f.get(&lr.Default), Max: *f.get(&lr.Max)})
+	}
+	return bounds
+}
+
+// PodBounds returns the range of the pod annotations the BandwidthRange covers, in the order of
+// policy.PodKeys. The bursts, which the validation forbids in a range of the pod network but which
+// older policies may still carry, are left out.
+func (lr BandwidthRange) PodBounds() policy.Range {
+	return lr.Bounds()[:len(policy.PodKeys)]
+}
+
+// bandwidthRangeFrom returns the BandwidthRange with the bounds of the range.
+func bandwidthRangeFrom(r policy.Range) BandwidthRange {
+	var lr BandwidthRange
+	for _, f := range itemFields {
+		b := r.Get(f.key)
+		*f.get(&lr.Min), *f.get(&lr.Default), *f.get(&lr.Max) = b.Min, b.Default, b.Max
+	}
+	return lr
+}
+
+// Policy returns the CustomLimitRange as a policy of the evaluation engine. A rule whose
+// podSelector is invalid matches no pod.
+func (r *CustomLimitRange) Policy() policy.Policy {
+	p := policy.Policy{
+		Kind:       "CustomLimitRange",
+		Namespace:  r.Namespace,
+		Name:       r.Name,
+		Generation: r.Generation,
+		Priority:   r.Spec.Priority,
+		Mode:       policy.Mode(r.Spec.EnforcementMode),
+		Range:      r.Spec.LRange.PodBounds(),
+	}
+	for _, rule := range r.Spec.Rules {
+		var selector labels.Selector
+		if rule.PodSelector != nil {
+			var err error
+			if selector, err = metav1.LabelSelectorAsSelector(rule.PodSelector); err != nil {
+				selector = labels.Nothing()
+			}
+		}
+		p.Rules = append(p.Rules, policy.Rule{
+			Selector: selector,
+			Range:    rule.PodBounds(),
+		})
+	}
+	for _, network := range r.Spec.Networks {
+		p.Networks = append(p.Networks, policy.NetworkRange{
+			Network: r.NetworkName(network.Name),
+			Range:   network.Bounds(),
+		})
+	}
+	return p
+}
+
+// NetworkName returns the namespace/name of a NetworkAttachmentDefinition named in the networks
+// of the CustomLimitRange, where a name without a namespace is in the namespace of the CustomLimitRange.
+func (r *CustomLimitRange) NetworkName(name string) string {
+	if strings.Contains(name, "/") {
+		return name
+	}
+	return r.Namespace + "/" + name
+}
+
+// Policies returns the CustomLimitRanges as policies of the evaluation engine.
+func Policies(items []CustomLimitRange) []policy.Policy {
+	policies := make([]policy.Policy, 0, len(items))
+	for i := range items {
+		policies = append(policies, items[i].Policy())
+	}
+	return policies
+}
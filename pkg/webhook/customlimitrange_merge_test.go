@@ -0,0 +1,181 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/policy"
+)
+
+func newMergeItem(name string, priority int32, max, min, def CustomItems) CustomLimitRange {
+	return CustomLimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-a"},
+		Spec: CustomLimitRangeSpec{
+			Priority: priority,
+			LRange:   LimitRange{Type: "Pod", BandwidthRange: BandwidthRange{Max: max, Min: min, Default: def}},
+		},
+	}
+}
+
+func TestMergePolicies(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	assert.Empty(policy.Merge(Policies(nil), nil).Range)
+
+	a := newMergeItem("a", 0,
+		CustomItems{Ingress: resource.MustParse("1G"), Egress: resource.MustParse("1G")},
+		CustomItems{Ingress: resource.MustParse("100M"), Egress: resource.MustParse("100M")},
+		CustomItems{Ingress: resource.MustParse("500M"), Egress: resource.MustParse("500M")})
+	b := newMergeItem("b", 0,
+		CustomItems{Ingress: resource.MustParse("800M")},
+		CustomItems{Ingress: resource.MustParse("10M"), Egress: resource.MustParse("200M")},
+		CustomItems{Ingress: resource.MustParse("300M"), Egress: resource.MustParse("300M")})
+
+	e := policy.Merge(Policies([]CustomLimitRange{b, a}), nil)
+	ingress, egress := e.Range.Get(common.IngressBandwidthAnnotation), e.Range.Get(common.EgressBandwidthAnnotation)
+	assert.Equal("800M", ingress.Max.String())
+	assert.Equal("1G", egress.Max.String())
+	assert.Equal("10M", ingress.Min.String())
+	assert.Equal("100M", egress.Min.String())
+	// equal priority: "a" wins by name
+	assert.Equal("500M", ingress.Default.String())
+	assert.Equal("500M", egress.Default.String())
+
+	b.Spec.Priority = 10
+	e = policy.Merge(Policies([]CustomLimitRange{a, b}), nil)
+	ingress, egress = e.Range.Get(common.IngressBandwidthAnnotation), e.Range.Get(common.EgressBandwidthAnnotation)
+	assert.Equal("300M", ingress.Default.String())
+	assert.Equal("300M", egress.Default.String())
+
+	// a min left unset by one policy is the loosest min
+	c := newMergeItem("c", 20, CustomItems{Egress: resource.MustParse("200M")}, CustomItems{},
+		CustomItems{Egress: resource.MustParse("900M")})
+	e = policy.Merge(Policies([]CustomLimitRange{a, b, c}), nil)
+	ingress, egress = e.Range.Get(common.IngressBandwidthAnnotation), e.Range.Get(common.EgressBandwidthAnnotation)
+	assert.True(ingress.Min.IsZero())
+	assert.True(egress.Min.IsZero())
+	assert.Equal("200M", egress.Max.String())
+	// the chosen default is clamped into the merged range
+	assert.Equal("200M", egress.Default.String())
+
+	assert.Equal(policy.BoundSource{Max: "test-a/b", Default: "test-a/b"}, e.Sources[common.IngressBandwidthAnnotation])
+	assert.Equal(policy.BoundSource{Max: "test-a/c", Default: "test-a/c"}, e.Sources[common.EgressBandwidthAnnotation])
+	assert.Equal(policy.BoundSource{}, e.Sources[common.IngressBurstKey])
+}
+
+func TestPolicyRangeFor(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	r := newMergeItem("rules", 0, CustomItems{Ingress: resource.MustParse("1G")}, CustomItems{},
+		CustomItems{Ingress: resource.MustParse("100M")})
+	r.Spec.Rules = []LimitRangeRule{
+		{
+			Name:        "database",
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			BandwidthRange: BandwidthRange{
+				Max:     CustomItems{Ingress: resource.MustParse("10G")},
+				Default: CustomItems{Ingress: resource.MustParse("5G")},
+			},
+		},
+		{
+			Name:           "batch",
+			PodSelector:    &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "batch"}},
+			BandwidthRange: BandwidthRange{Default: CustomItems{Ingress: resource.MustParse("10M")}},
+		},
+		{
+			Name:           "web",
+			PodSelector:    &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "web"}},
+			BandwidthRange: BandwidthRange{Default: CustomItems{Ingress: resource.MustParse("200M")}},
+		},
+	}
+
+	p := r.Policy()
+	ingressDefault := func(r policy.Range) string {
+		b := r.Get(common.IngressBandwidthAnnotation)
+		return b.Default.String()
+	}
+	assert.Equal("5G", ingressDefault(p.RangeFor(map[string]string{"app": "db", "tier": "batch"})))
+	assert.Equal("10M", ingressDefault(p.RangeFor(map[string]string{"tier": "batch"})))
+	assert.Equal("100M", ingressDefault(p.RangeFor(map[string]string{"tier": "frontend"})))
+	assert.Equal("100M", ingressDefault(p.RangeFor(nil)))
+
+	b := policy.Merge(Policies([]CustomLimitRange{r}), map[string]string{"app": "db"}).Range.Get(common.IngressBandwidthAnnotation)
+	assert.Equal("10G", b.Max.String())
+	assert.Equal("5G", b.Default.String())
+
+	// a rule without selector selects every remaining pod
+	r.Spec.Rules = append(r.Spec.Rules, LimitRangeRule{Name: "rest", BandwidthRange: BandwidthRange{Default: CustomItems{Ingress: resource.MustParse("1M")}}})
+	p = r.Policy()
+	assert.Equal("1M", ingressDefault(p.RangeFor(nil)))
+}
+
+func TestCustomLimitRangeOverlapWarnings(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = AddToScheme(scheme)
+
+	existing := newMergeItem("a", 0, CustomItems{Ingress: resource.MustParse("1G")}, CustomItems{},
+		CustomItems{Ingress: resource.MustParse("500M")})
+	v := &CustomLimitRangeValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(&existing).Build()}
+	ctx := context.Background()
+
+	r := newMergeItem("b", 0, CustomItems{Ingress: resource.MustParse("800M")}, CustomItems{},
+		CustomItems{Ingress: resource.MustParse("300M")})
+	// a second CustomLimitRange with the same priority is ambiguous
+	_, err := v.ValidateCreate(ctx, &r)
+	assert.True(apierrors.IsInvalid(err))
+	assert.ErrorContains(err, "spec.priority")
+	assert.ErrorContains(err, "test-a/a")
+
+	r.Spec.Priority = 1
+	warnings, err := v.ValidateCreate(ctx, &r)
+	assert.Nil(err)
+	assert.Len(warnings, 2)
+
+	warnings, err = v.ValidateUpdate(ctx, &existing, &existing)
+	assert.Nil(err)
+	assert.Empty(warnings)
+
+	// an update may keep a priority that is already shared, but not move to one
+	shared := r.DeepCopy()
+	shared.Spec.Priority = 0
+	edited := shared.DeepCopy()
+	edited.Spec.LRange.Max.Ingress = resource.MustParse("700M")
+	_, err = v.ValidateUpdate(ctx, shared, edited)
+	assert.Nil(err)
+	_, err = v.ValidateUpdate(ctx, &r, shared)
+	assert.True(apierrors.IsInvalid(err))
+
+	_, err = v.ValidateCreate(ctx, &ClusterCustomLimitRange{})
+	assert.NotNil(err)
+}
@@ -27,6 +27,9 @@ var (
 	// GroupVersion is group version used to register these objects
 	GroupVersion = schema.GroupVersion{Group: "custom.cmss.com", Version: "v1"}
 
+	// SchemeGroupVersion is GroupVersion under the name the generated clientset expects.
+	SchemeGroupVersion = GroupVersion
+
 	// SchemeBuilder is used to add go types to the GroupVersionKind scheme
 	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
 
@@ -34,27 +37,188 @@ var (
 	AddToScheme = SchemeBuilder.AddToScheme
 )
 
+// Resource takes an unqualified resource and returns a Group qualified GroupResource.
+func Resource(resource string) schema.GroupResource {
+	return SchemeGroupVersion.WithResource(resource).GroupResource()
+}
+
+// The fields are omitzero rather than omitempty, which does not apply to structs, so that unset
+// quantities are not serialized as "0" by the defaulting webhook. The validation rules mirror
+// validateItems so that invalid policies are rejected even when the webhook is down.
+// +kubebuilder:validation:XValidation:rule="!has(self.ingress__dash__burst) || !has(self.ingress__dash__bandwidth) || quantity(string(self.ingress__dash__burst)).asApproximateFloat() * 1000.0 >= quantity(string(self.ingress__dash__bandwidth)).asApproximateFloat()",message="ingress-burst: burst must hold at least 1ms of traffic at the rate (burst >= rate/1000)"
+// +kubebuilder:validation:XValidation:rule="!has(self.egress__dash__burst) || !has(self.egress__dash__bandwidth) || quantity(string(self.egress__dash__burst)).asApproximateFloat() * 1000.0 >= quantity(string(self.egress__dash__bandwidth)).asApproximateFloat()",message="egress-burst: burst must hold at least 1ms of traffic at the rate (burst >= rate/1000)"
+
+// CustomItems holds ingress/egress bandwidth rates and token bucket sizes, in bits.
 type CustomItems struct {
-	Ingress resource.Quantity `json:"ingress-bandwidth,omitempty"`
-	Egress  resource.Quantity `json:"egress-bandwidth,omitempty"`
+	// +optional
+	// +kubebuilder:validation:XIntOrString
+	// +kubebuilder:validation:MaxLength=32
+	// +kubebuilder:validation:XValidation:rule="quantity(string(self)).compareTo(quantity('1k')) >= 0 && quantity(string(self)).compareTo(quantity('1P')) <= 0",message="resource is unreasonably small (< 1kbit) or large (> 1Pbit)"
+	Ingress resource.Quantity `json:"ingress-bandwidth,omitzero"`
+	// +optional
+	// +kubebuilder:validation:XIntOrString
+	// +kubebuilder:validation:MaxLength=32
+	// +kubebuilder:validation:XValidation:rule="quantity(string(self)).compareTo(quantity('1k')) >= 0 && quantity(string(self)).compareTo(quantity('1P')) <= 0",message="resource is unreasonably small (< 1kbit) or large (> 1Pbit)"
+	Egress resource.Quantity `json:"egress-bandwidth,omitzero"`
+	// IngressBurst and EgressBurst are the token bucket sizes, in bits, of the bandwidth plugin. They
+	// only bound Multus networks, in spec.networks: the container runtimes hard-code the burst of
+	// the pod network.
+	// +optional
+	// +kubebuilder:validation:XIntOrString
+	// +kubebuilder:validation:MaxLength=32
+	// +kubebuilder:validation:XValidation:rule="quantity(string(self)).compareTo(quantity('1k')) >= 0 && quantity(string(self)).compareTo(quantity('1P')) <= 0",message="resource is unreasonably small (< 1kbit) or large (> 1Pbit)"
+	IngressBurst resource.Quantity `json:"ingress-burst,omitzero"`
+	// +optional
+	// +kubebuilder:validation:XIntOrString
+	// +kubebuilder:validation:MaxLength=32
+	// +kubebuilder:validation:XValidation:rule="quantity(string(self)).compareTo(quantity('1k')) >= 0 && quantity(string(self)).compareTo(quantity('1P')) <= 0",message="resource is unreasonably small (< 1kbit) or large (> 1Pbit)"
+	EgressBurst resource.Quantity `json:"egress-burst,omitzero"`
 }
 
-type LimitRange struct {
-	Type    string      `json:"type"`
+// LimitRangeTypePod is the canonical LimitRange type. The CRD also accepts "pod" and "POD".
+const LimitRangeTypePod = "Pod"
+
+// BandwidthRange is the max, min and default of a bandwidth range. Its validation rules require
+// min <= default <= max.
+// +kubebuilder:validation:XValidation:rule="!has(self.min) || !has(self.max) || !has(self.min.ingress__dash__bandwidth) || !has(self.max.ingress__dash__bandwidth) || quantity(string(self.min.ingress__dash__bandwidth)).compareTo(quantity(string(self.max.ingress__dash__bandwidth))) <= 0",message="min.ingress-bandwidth must be less than or equal to max.ingress-bandwidth"
+// +kubebuilder:validation:XValidation:rule="!has(self.min) || !has(self.default) || !has(self.min.ingress__dash__bandwidth) || !has(self.default.ingress__dash__bandwidth) || quantity(string(self.min.ingress__dash__bandwidth)).compareTo(quantity(string(self.default.ingress__dash__bandwidth))) <= 0",message="default.ingress-bandwidth must be greater than or equal to min.ingress-bandwidth"
+// +kubebuilder:validation:XValidation:rule="!has(self.default) || !has(self.max) || !has(self.default.ingress__dash__bandwidth) || !has(self.max.ingress__dash__bandwidth) || quantity(string(self.default.ingress__dash__bandwidth)).compareTo(quantity(string(self.max.ingress__dash__bandwidth))) <= 0",message="default.ingress-bandwidth must be less than or equal to max.ingress-bandwidth"
+// +kubebuilder:validation:XValidation:rule="!has(self.min) || !has(self.max) || !has(self.min.egress__dash__bandwidth) || !has(self.max.egress__dash__bandwidth) || quantity(string(self.min.egress__dash__bandwidth)).compareTo(quantity(string(self.max.egress__dash__bandwidth))) <= 0",message="min.egress-bandwidth must be less than or equal to max.egress-bandwidth"
+// +kubebuilder:validation:XValidation:rule="!has(self.min) || !has(self.default) || !has(self.min.egress__dash__bandwidth) || !has(self.default.egress__dash__bandwidth) || quantity(string(self.min.egress__dash__bandwidth)).compareTo(quantity(string(self.default.egress__dash__bandwidth))) <= 0",message="default.egress-bandwidth must be greater than or equal to min.egress-bandwidth"
+// +kubebuilder:validation:XValidation:rule="!has(self.default) || !has(self.max) || !has(self.default.egress__dash__bandwidth) || !has(self.max.egress__dash__bandwidth) || quantity(string(self.default.egress__dash__bandwidth)).compareTo(quantity(string(self.max.egress__dash__bandwidth))) <= 0",message="default.egress-bandwidth must be less than or equal to max.egress-bandwidth"
+// +kubebuilder:validation:XValidation:rule="!has(self.min) || !has(self.max) || !has(self.min.ingress__dash__burst) || !has(self.max.ingress__dash__burst) || quantity(string(self.min.ingress__dash__burst)).compareTo(quantity(string(self.max.ingress__dash__burst))) <= 0",message="min.ingress-burst must be less than or equal to max.ingress-burst"
+// +kubebuilder:validation:XValidation:rule="!has(self.min) || !has(self.default) || !has(self.min.ingress__dash__burst) || !has(self.default.ingress__dash__burst) || quantity(string(self.min.ingress__dash__burst)).compareTo(quantity(string(self.default.ingress__dash__burst))) <= 0",message="default.ingress-burst must be greater than or equal to min.ingress-burst"
+// +kubebuilder:validation:XValidation:rule="!has(self.default) || !has(self.max) || !has(self.default.ingress__dash__burst) || !has(self.max.ingress__dash__burst) || quantity(string(self.default.ingress__dash__burst)).compareTo(quantity(string(self.max.ingress__dash__burst))) <= 0",message="default.ingress-burst must be less than or equal to max.ingress-burst"
+// +kubebuilder:validation:XValidation:rule="!has(self.min) || !has(self.max) || !has(self.min.egress__dash__burst) || !has(self.max.egress__dash__burst) || quantity(string(self.min.egress__dash__burst)).compareTo(quantity(string(self.max.egress__dash__burst))) <= 0",message="min.egress-burst must be less than or equal to max.egress-burst"
+// +kubebuilder:validation:XValidation:rule="!has(self.min) || !has(self.default) || !has(self.min.egress__dash__burst) || !has(self.default.egress__dash__burst) || quantity(string(self.min.egress__dash__burst)).compareTo(quantity(string(self.default.egress__dash__burst))) <= 0",message="default.egress-burst must be greater than or equal to min.egress-burst"
+// +kubebuilder:validation:XValidation:rule="!has(self.default) || !has(self.max) || !has(self.default.egress__dash__burst) || !has(self.max.egress__dash__burst) || quantity(string(self.default.egress__dash__burst)).compareTo(quantity(string(self.max.egress__dash__burst))) <= 0",message="default.egress-burst must be less than or equal to max.egress-burst"
+type BandwidthRange struct {
 	Max     CustomItems `json:"max,omitempty"`
 	Min     CustomItems `json:"min,omitempty"`
 	Default CustomItems `json:"default,omitempty"`
 }
 
+// LimitRange bounds the bandwidth of pods. It takes no bursts, see CustomItems.
+// +kubebuilder:validation:XValidation:rule="!self.?max.?ingress__dash__burst.hasValue() && !self.?max.?egress__dash__burst.hasValue() && !self.?min.?ingress__dash__burst.hasValue() && !self.?min.?egress__dash__burst.hasValue() && !self.?default.?ingress__dash__burst.hasValue() && !self.?default.?egress__dash__burst.hasValue()",message="bursts are only supported in spec.networks, the container runtimes do not shape the burst of the pod network"
+type LimitRange struct {
+	// +kubebuilder:default=Pod
+	// +kubebuilder:validation:Enum=pod;Pod;POD
+	Type           string `json:"type"`
+	BandwidthRange `json:",inline"`
+}
+
+// EnforcementMode controls what happens to pods whose bandwidth is out of range.
+// +kubebuilder:validation:Enum=enforce;clamp;warn;audit;dryRun
+type EnforcementMode string
+
+const (
+	// EnforcementModeEnforce rejects out of range pods. It is the default.
+	EnforcementModeEnforce EnforcementMode = "enforce"
+	// EnforcementModeClamp rewrites out of range pod bandwidth to the nearest bound, keeps the
+	// requested value in a customlimitrange.kubernetes.io/requested-* annotation and returns a warning.
+	EnforcementModeClamp EnforcementMode = "clamp"
+	// EnforcementModeWarn admits out of range pods and returns admission warnings.
+	EnforcementModeWarn EnforcementMode = "warn"
+	// EnforcementModeAudit admits out of range pods and only logs and records the violation.
+	EnforcementModeAudit EnforcementMode = "audit"
+	// EnforcementModeDryRun computes defaults and violations without patching or rejecting pods.
+	EnforcementModeDryRun EnforcementMode = "dryRun"
+)
+
+// DefaultPolicy fills the unset defaults of a range from its other bounds.
+// +kubebuilder:validation:Enum=none;min;max
+type DefaultPolicy string
+
+const (
+	// DefaultPolicyNone leaves unset defaults empty. It is the default.
+	DefaultPolicyNone DefaultPolicy = "none"
+	// DefaultPolicyMin sets an unset default to the min of the same field.
+	DefaultPolicyMin DefaultPolicy = "min"
+	// DefaultPolicyMax sets an unset default to the max of the same field.
+	DefaultPolicyMax DefaultPolicy = "max"
+)
+
+// LimitRangeRule applies its own range to the pods selected by PodSelector. It takes no bursts.
+// +kubebuilder:validation:XValidation:rule="!self.?max.?ingress__dash__burst.hasValue() && !self.?max.?egress__dash__burst.hasValue() && !self.?min.?ingress__dash__burst.hasValue() && !self.?min.?egress__dash__burst.hasValue() && !self.?default.?ingress__dash__burst.hasValue() && !self.?default.?egress__dash__burst.hasValue()",message="bursts are only supported in spec.networks, the container runtimes do not shape the burst of the pod network"
+type LimitRangeRule struct {
+	Name string `json:"name,omitempty"`
+	// PodSelector selects the pods the rule applies to. A nil selector selects every pod.
+	PodSelector    *metav1.LabelSelector `json:"podSelector,omitempty"`
+	BandwidthRange `json:",inline"`
+}
+
+// NetworkLimitRange bounds the bandwidth of the interfaces that pods attach to a Multus secondary
+// network, in the bandwidth of their network selection in the k8s.v1.cni.cncf.io/networks annotation.
+type NetworkLimitRange struct {
+	// Name is the NetworkAttachmentDefinition, as namespace/name, or as name in the namespace of
+	// the CustomLimitRange.
+	// +kubebuilder:validation:MinLength=1
+	Name           string `json:"name"`
+	BandwidthRange `json:",inline"`
+}
+
 // CustomLimitRangeSpec defines the desired state of CustomLimitRange
 type CustomLimitRangeSpec struct {
+	// LRange is the catch-all range for pods not selected by any rule.
 	LRange LimitRange `json:"limitrange"`
+	// Rules are evaluated in order; the first rule selecting a pod replaces the catch-all range.
+	// +kubebuilder:validation:MaxItems=64
+	Rules []LimitRangeRule `json:"rules,omitempty"`
+	// Networks bound the bandwidth of the interfaces of Multus secondary networks, by
+	// NetworkAttachmentDefinition. The catch-all range and the rules bound the cluster network.
+	// +kubebuilder:validation:MaxItems=32
+	// +listType=map
+	// +listMapKey=name
+	Networks []NetworkLimitRange `json:"networks,omitempty"`
+	// EnforcementMode is one of enforce (default), clamp, warn, audit or dryRun.
+	EnforcementMode EnforcementMode `json:"enforcementMode,omitempty"`
+	// Priority orders the defaults of the CustomLimitRanges of a namespace, which may hold several of
+	// them; the highest priority wins, and two of them may not share a priority.
+	Priority int32 `json:"priority,omitempty"`
+	// DefaultPolicy is one of none (default), min or max. Defaults it fills are listed in the
+	// customlimitrange.kubernetes.io/defaulted annotation.
+	DefaultPolicy DefaultPolicy `json:"defaultPolicy,omitempty"`
 }
 
+const (
+	// ConditionReady reports whether the CustomLimitRange is in effect for pods in its namespace.
+	ConditionReady = "Ready"
+	// ConditionConflicting reports whether another CustomLimitRange in the same namespace competes with this one.
+	ConditionConflicting = "Conflicting"
+)
+
 // CustomLimitRangeStatus defines the observed state of CustomLimitRange
 type CustomLimitRangeStatus struct {
+	// ObservedGeneration is the most recent generation evaluated by the controller.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the current state of the policy (Ready, Conflicting).
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// CompliantPods is the number of pods whose bandwidth annotations are within range.
+	CompliantPods int32 `json:"compliantPods"`
+	// DefaultedPods is the number of pods carrying the policy default bandwidth.
+	DefaultedPods int32 `json:"defaultedPods"`
+	// OutOfRangePods is the number of pods whose bandwidth annotations violate the range.
+	OutOfRangePods int32 `json:"outOfRangePods"`
+	// LastEvaluationTime is the last time the controller evaluated the pods in the namespace.
+	LastEvaluationTime *metav1.Time `json:"lastEvaluationTime,omitempty"`
 }
 
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=clr
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.spec.enforcementMode`
+// +kubebuilder:printcolumn:name="Priority",type=integer,JSONPath=`.spec.priority`
+// +kubebuilder:printcolumn:name="Conflicting",type=string,JSONPath=`.status.conditions[?(@.type=="Conflicting")].status`
+// +kubebuilder:printcolumn:name="Compliant",type=integer,JSONPath=`.status.compliantPods`
+// +kubebuilder:printcolumn:name="Defaulted",type=integer,JSONPath=`.status.defaultedPods`
+// +kubebuilder:printcolumn:name="OutOfRange",type=integer,JSONPath=`.status.outOfRangePods`
+// +kubebuilder:printcolumn:name="LastEvaluated",type=date,JSONPath=`.status.lastEvaluationTime`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
 // CustomLimitRange is the Schema for the customlimitranges API
 type CustomLimitRange struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -64,6 +228,8 @@ type CustomLimitRange struct {
 	Status CustomLimitRangeStatus `json:"status,omitempty"`
 }
 
+// +kubebuilder:object:root=true
+
 // CustomLimitRangeList contains a list of CustomLimitRange
 type CustomLimitRangeList struct {
 	metav1.TypeMeta `json:",inline"`
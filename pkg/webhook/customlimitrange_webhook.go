@@ -18,17 +18,26 @@ package webhook
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
 
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	wk "sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/policy"
 )
 
 // log is for logging in this package.
@@ -37,7 +46,7 @@ var customlimitrangelog = logf.Log.WithName("customlimitrange-resource")
 func (r *CustomLimitRange) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
-		WithValidator(&CustomLimitRange{}).
+		WithValidator(&CustomLimitRangeValidator{Client: mgr.GetClient()}).
 		WithDefaulter(&CustomLimitRange{}).
 		Complete()
 }
@@ -46,115 +55,262 @@ var _ wk.CustomDefaulter = &CustomLimitRange{}
 
 // Default implements webhook.Defaulter so a webhook will be registered for the type
 func (r *CustomLimitRange) Default(ctx context.Context, obj runtime.Object) error {
-	customlimitrangelog.Info("default", "name", r.Name, "request", r)
-	customlimitrangelog.Info("Spec", "spec", r.Spec)
+	c, ok := obj.(*CustomLimitRange)
+	if !ok {
+		return fmt.Errorf("expected a CustomLimitRange but got a %T", obj)
+	}
+	customlimitrangelog.Info("default", "name", c.Name, "request", c)
+
+	var old *CustomLimitRange
+	if req, err := admission.RequestFromContext(ctx); err == nil && req.Operation == admissionv1.Update && len(req.OldObject.Raw) > 0 {
+		old = &CustomLimitRange{}
+		if err := json.Unmarshal(req.OldObject.Raw, old); err != nil {
+			return fmt.Errorf("decode old CustomLimitRange: %w", err)
+		}
+	}
+	c.applyDefaults(old)
 	return nil
 }
 
-var _ wk.CustomValidator = &CustomLimitRange{}
-var minRsrc = resource.MustParse("1k")
-var maxRsrc = resource.MustParse("1P")
+// +kubebuilder:object:generate=false
+
+// CustomLimitRangeValidator validates CustomLimitRange objects and warns about
+// other CustomLimitRanges in the same namespace they are merged with.
+type CustomLimitRangeValidator struct {
+	Client client.Client
+}
+
+var _ wk.CustomValidator = &CustomLimitRangeValidator{}
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
-func (r *CustomLimitRange) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+func (v *CustomLimitRangeValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	r, ok := obj.(*CustomLimitRange)
+	if !ok {
+		return nil, fmt.Errorf("expected a CustomLimitRange but got a %T", obj)
+	}
 	customlimitrangelog.Info("validate create", "name", r.Name, "request", r)
-	var allErrs field.ErrorList
-	err := bandwidthValidateIsReasonable(r.Spec.LRange.Min, r.Spec.LRange.Default, r.Spec.LRange.Max)
-	if err != nil {
-		allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("LRange"),
-			r.Spec.LRange,
-			err.Error()))
-	}
-	customlimitrangelog.Info("validate bandwidthValidateIsReasonable", "err", err, "field.ErrorList", allErrs)
-	if len(allErrs) == 0 {
-		return nil, nil
+	items, listErr := v.listNamespace(ctx, r.Namespace)
+	allErrs := r.validateFields()
+	allErrs = append(allErrs, validatePriority(r, nil, items, listErr)...)
+	if len(allErrs) > 0 {
+		return nil, r.invalid(allErrs)
 	}
 
-	return nil, errors.NewInvalid(r.GroupVersionKind().GroupKind(), r.Name, allErrs)
+	return overlapWarnings(r, items, listErr), nil
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
-func (r *CustomLimitRange) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+func (v *CustomLimitRangeValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	r, ok := newObj.(*CustomLimitRange)
+	if !ok {
+		return nil, fmt.Errorf("expected a CustomLimitRange but got a %T", newObj)
+	}
+	old, ok := oldObj.(*CustomLimitRange)
+	if !ok {
+		return nil, fmt.Errorf("expected a CustomLimitRange but got a %T", oldObj)
+	}
 	customlimitrangelog.Info("validate update", "name", r.Name, "request", r)
-	var allErrs field.ErrorList
-	err := bandwidthValidateIsReasonable(r.Spec.LRange.Min, r.Spec.LRange.Default, r.Spec.LRange.Max)
-	if err != nil {
-		allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("LRange"),
-			r.Spec.LRange,
-			err.Error()))
-	}
-	customlimitrangelog.Info("validate bandwidthValidateIsReasonable", "err", err, "field.ErrorList", allErrs)
-	if len(allErrs) == 0 {
+	// a status or metadata update leaves the range, and the pods it bounds, unchanged
+	if equality.Semantic.DeepEqual(old.Spec, r.Spec) {
 		return nil, nil
 	}
+	items, listErr := v.listNamespace(ctx, r.Namespace)
+	allErrs := r.validateFields()
+	allErrs = append(allErrs, validatePriority(r, old, items, listErr)...)
+	if len(allErrs) > 0 {
+		return nil, r.invalid(allErrs)
+	}
 
-	return nil, errors.NewInvalid(r.GroupVersionKind().GroupKind(), r.Name, allErrs)
+	warnings := overlapWarnings(r, items, listErr)
+	return append(warnings, v.impactWarnings(ctx, old, r, items, listErr)...), nil
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
-func (r *CustomLimitRange) ValidateDelete(cxt context.Context, obj runtime.Object) (admission.Warnings, error) {
-	customlimitrangelog.Info("validate delete", "name", r.Name)
-	return nil, nil
+func (v *CustomLimitRangeValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	customlimitrangelog.Info("validate delete", "obj", obj)
+	r, ok := obj.(*CustomLimitRange)
+	if !ok {
+		return nil, fmt.Errorf("expected a CustomLimitRange but got a %T", obj)
+	}
+
+	items, listErr := v.listNamespace(ctx, r.Namespace)
+	return v.impactWarnings(ctx, r, nil, items, listErr), nil
 }
 
-func bandwidthValidateIsReasonable(min, def, max CustomItems) error {
-	if err := bandwidthValidate(min); err != nil {
-		return err
+// listNamespace lists the CustomLimitRanges of the namespace once per request, for the priority
+// check and the warnings to share.
+func (v *CustomLimitRangeValidator) listNamespace(ctx context.Context, namespace string) ([]CustomLimitRange, error) {
+	if v.Client == nil {
+		return nil, nil
 	}
-
-	if err := bandwidthValidate(def); err != nil {
-		return err
+	clrl := &CustomLimitRangeList{}
+	if err := v.Client.List(ctx, clrl, client.InNamespace(namespace)); err != nil {
+		customlimitrangelog.Info("list CustomLimitRange error", "namespace", namespace, "err", err)
+		return nil, err
 	}
+	return clrl.Items, nil
+}
 
-	if err := bandwidthValidate(max); err != nil {
-		return err
-	}
+func (r *CustomLimitRange) invalid(allErrs field.ErrorList) error {
+	return errors.NewInvalid(GroupVersion.WithKind("CustomLimitRange").GroupKind(), r.Name, allErrs)
+}
 
-	if !min.Egress.IsZero() && !def.Egress.IsZero() && min.Egress.Value() > def.Egress.Value() {
-		return common.ErrInvalidBandwidthMaxMin
+// validateFields reports every invalid field of the CustomLimitRange under its path.
+func (r *CustomLimitRange) validateFields() field.ErrorList {
+	lr := r.Spec.LRange
+	allErrs := validateItems(lr.Min, lr.Default, lr.Max, field.NewPath("spec", "limitrange"))
+	allErrs = append(allErrs, validatePodBursts(lr.BandwidthRange, field.NewPath("spec", "limitrange"))...)
+	if err := validateEnforcementMode(r.Spec.EnforcementMode); err != nil {
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("spec").Child("enforcementMode"),
+			r.Spec.EnforcementMode, enforcementModes))
 	}
-	if !min.Ingress.IsZero() && !def.Ingress.IsZero() && min.Ingress.Value() > def.Ingress.Value() {
-		return common.ErrInvalidBandwidthMaxMin
+	if err := validateDefaultPolicy(r.Spec.DefaultPolicy); err != nil {
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("spec").Child("defaultPolicy"),
+			r.Spec.DefaultPolicy, defaultPolicies))
 	}
-
-	if !min.Egress.IsZero() && !max.Egress.IsZero() && min.Egress.Value() > max.Egress.Value() {
-		return common.ErrInvalidBandwidthMaxMin
+	for i, rule := range r.Spec.Rules {
+		rulePath := field.NewPath("spec").Child("rules").Index(i)
+		if rule.PodSelector != nil {
+			if _, err := metav1.LabelSelectorAsSelector(rule.PodSelector); err != nil {
+				allErrs = append(allErrs, field.Invalid(rulePath.Child("podSelector"), rule.PodSelector, err.Error()))
+			}
+		}
+		allErrs = append(allErrs, validateItems(rule.Min, rule.Default, rule.Max, rulePath)...)
+		allErrs = append(allErrs, validatePodBursts(rule.BandwidthRange, rulePath)...)
 	}
-	if !min.Ingress.IsZero() && !max.Ingress.IsZero() && min.Ingress.Value() > max.Ingress.Value() {
-		return common.ErrInvalidBandwidthMaxMin
+	networks := map[string]bool{}
+	for i, network := range r.Spec.Networks {
+		networkPath := field.NewPath("spec").Child("networks").Index(i)
+		name := r.NetworkName(network.Name)
+		if msgs := validateNetworkName(network.Name); len(msgs) > 0 {
+			allErrs = append(allErrs, field.Invalid(networkPath.Child("name"), network.Name, strings.Join(msgs, "; ")))
+		} else if networks[name] {
+			allErrs = append(allErrs, field.Duplicate(networkPath.Child("name"), network.Name))
+		}
+		networks[name] = true
+		allErrs = append(allErrs, validateItems(network.Min, network.Default, network.Max, networkPath)...)
 	}
+	customlimitrangelog.Info("validate fields", "field.ErrorList", allErrs)
+	return allErrs
+}
 
-	if !def.Egress.IsZero() && !max.Egress.IsZero() && def.Egress.Value() > max.Egress.Value() {
-		return common.ErrInvalidBandwidthMaxMin
+// validateNetworkName validates the name of a NetworkAttachmentDefinition, written as
+// namespace/name or name.
+func validateNetworkName(network string) []string {
+	namespace, name, found := strings.Cut(network, "/")
+	if !found {
+		return validation.IsDNS1123Subdomain(network)
 	}
-	if !def.Ingress.IsZero() && !max.Ingress.IsZero() && def.Ingress.Value() > max.Ingress.Value() {
-		return common.ErrInvalidBandwidthMaxMin
+	msgs := validation.IsDNS1123Label(namespace)
+	return append(msgs, validation.IsDNS1123Subdomain(name)...)
+}
+
+// validatePriority rejects a CustomLimitRange whose priority is already used by another
+// CustomLimitRange of the namespace, since the order in which their defaults apply would be
+// ambiguous. An update keeping a priority that was already shared is allowed, so that
+// existing policies can still be edited. items are the CustomLimitRanges of the namespace, or
+// listErr the error listing them.
+func validatePriority(r, old *CustomLimitRange, items []CustomLimitRange, listErr error) field.ErrorList {
+	if old != nil && old.Spec.Priority == r.Spec.Priority {
+		return nil
 	}
 
+	path := field.NewPath("spec", "priority")
+	if listErr != nil {
+		return field.ErrorList{field.InternalError(path, listErr)}
+	}
+	for _, item := range items {
+		if item.Name == r.Name || item.Spec.Priority != r.Spec.Priority {
+			continue
+		}
+		return field.ErrorList{field.Forbidden(path, fmt.Sprintf("priority %d is already used by CustomLimitRange %s/%s, priorities must be unique in a namespace",
+			r.Spec.Priority, item.Namespace, item.Name))}
+	}
 	return nil
 }
 
-func bandwidthValidate(item CustomItems) error {
-	if !item.Egress.IsZero() {
-		if err := validateBandwidthIsReasonable(item.Egress); err != nil {
-			return err
+// validateItems reports, under path, every bound of min, default and max that is unreasonably
+// small or large, whose burst cannot sustain its rate, or that is not within min <= default <= max.
+func validateItems(min, def, max CustomItems, path *field.Path) field.ErrorList {
+	names := make(map[string]string, len(itemFields))
+	for _, f := range itemFields {
+		names[f.key] = f.name
+	}
+
+	var allErrs field.ErrorList
+	for _, err := range policy.ValidateRange(BandwidthRange{Min: min, Default: def, Max: max}.Bounds()) {
+		allErrs = append(allErrs, field.Invalid(path.Child(err.Limit, names[err.Key]), err.Value.String(), err.Detail))
+	}
+	return allErrs
+}
+
+// validatePodBursts forbids the bursts of a range of the pod network: the container runtimes
+// hard-code its burst, only the ranges of spec.networks take bursts.
+func validatePodBursts(r BandwidthRange, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for _, limit := range []struct {
+		name  string
+		items CustomItems
+	}{{"min", r.Min}, {"default", r.Default}, {"max", r.Max}} {
+		for _, f := range itemFields {
+			if slices.Contains(policy.PodKeys, f.key) || f.get(&limit.items).IsZero() {
+				continue
+			}
+			allErrs = append(allErrs, field.Forbidden(path.Child(limit.name, f.name),
+				"bursts are only supported in spec.networks, the container runtimes do not shape the burst of the pod network"))
 		}
 	}
-	if !item.Ingress.IsZero() {
-		if err := validateBandwidthIsReasonable(item.Ingress); err != nil {
-			return err
+	return allErrs
+}
+
+// overlapWarnings describes how the other CustomLimitRanges of the namespace, items, are merged
+// with r.
+func overlapWarnings(r *CustomLimitRange, items []CustomLimitRange, listErr error) admission.Warnings {
+	if listErr != nil {
+		return admission.Warnings{fmt.Sprintf("unable to check other CustomLimitRanges in namespace %s: %v", r.Namespace, listErr)}
+	}
+
+	var warnings admission.Warnings
+	merged := []CustomLimitRange{*r}
+	for _, item := range items {
+		if item.Name == r.Name {
+			continue
 		}
+		merged = append(merged, item)
+		warnings = append(warnings, fmt.Sprintf("CustomLimitRange %s/%s overlaps with %s/%s: the tightest max and loosest min apply",
+			r.Namespace, r.Name, item.Namespace, item.Name))
+	}
+	if len(merged) > 1 {
+		lr := bandwidthRangeFrom(policy.Merge(Policies(merged), nil).Range)
+		warnings = append(warnings, fmt.Sprintf("effective catch-all range in namespace %s: max %s, min %s, default %s",
+			r.Namespace, formatItems(lr.Max), formatItems(lr.Min), formatItems(lr.Default)))
 	}
 
-	return nil
+	return warnings
+}
+
+func formatItems(item CustomItems) string {
+	s := fmt.Sprintf("{ingress-bandwidth: %s, egress-bandwidth: %s", item.Ingress.String(), item.Egress.String())
+	if !item.IngressBurst.IsZero() || !item.EgressBurst.IsZero() {
+		s += fmt.Sprintf(", ingress-burst: %s, egress-burst: %s", item.IngressBurst.String(), item.EgressBurst.String())
+	}
+	return s + "}"
+}
+
+var enforcementModes = []string{
+	string(EnforcementModeEnforce),
+	string(EnforcementModeClamp),
+	string(EnforcementModeWarn),
+	string(EnforcementModeAudit),
+	string(EnforcementModeDryRun),
 }
 
-func validateBandwidthIsReasonable(rsrc resource.Quantity) error {
-	if rsrc.Value() < minRsrc.Value() {
-		return common.ErrInvalidBandwidthRange
+func validateEnforcementMode(mode EnforcementMode) error {
+	if mode == "" {
+		return nil
 	}
-	if rsrc.Value() > maxRsrc.Value() {
-		return common.ErrInvalidBandwidthRange
+	if !policy.Mode(mode).Valid() {
+		return common.ErrInvalidEnforcementMode
 	}
 	return nil
 }
@@ -18,28 +18,43 @@ package webhook
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/kubeservice-stack/custom-limit-range/pkg/common"
+	"github.com/kubeservice-stack/custom-limit-range/pkg/policy"
 	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
+// validateRange returns the first error of policy.ValidateRange for the bounds.
+func validateRange(min, def, max CustomItems) error {
+	if errs := policy.ValidateRange(BandwidthRange{Min: min, Default: def, Max: max}.Bounds()); len(errs) > 0 {
+		return errs[0].Err
+	}
+	return nil
+}
+
 func TestValidateBandwidthIsReasonable(t *testing.T) {
 	assert := assert.New(t)
-	err := validateBandwidthIsReasonable(resource.MustParse("124"))
+	err := policy.ValidateQuantity(resource.MustParse("124"))
 	assert.ErrorIs(err, common.ErrInvalidBandwidthRange)
 
-	err = validateBandwidthIsReasonable(resource.MustParse("0.1k"))
+	err = policy.ValidateQuantity(resource.MustParse("0.1k"))
 	assert.ErrorIs(err, common.ErrInvalidBandwidthRange)
 
-	err = validateBandwidthIsReasonable(resource.MustParse("1124"))
+	err = policy.ValidateQuantity(resource.MustParse("1124"))
 	assert.Nil(err)
 
-	err = validateBandwidthIsReasonable(resource.MustParse("1.1P"))
+	err = policy.ValidateQuantity(resource.MustParse("1.1P"))
 	assert.ErrorIs(err, common.ErrInvalidBandwidthRange)
 
-	err = validateBandwidthIsReasonable(resource.MustParse("1001T"))
+	err = policy.ValidateQuantity(resource.MustParse("1001T"))
 	assert.ErrorIs(err, common.ErrInvalidBandwidthRange)
 }
 
@@ -90,14 +105,14 @@ func TestBandwidthValidate(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			err := bandwidthValidate(CustomItems{Ingress: tc.ingress, Egress: tc.egress})
+			err := validateRange(CustomItems{Ingress: tc.ingress, Egress: tc.egress}, CustomItems{}, CustomItems{})
 			assert.ErrorIs(err, tc.expected, tc.name)
 		})
 	}
 
-	err := bandwidthValidate(CustomItems{Ingress: resource.MustParse("2k")})
+	err := validateRange(CustomItems{Ingress: resource.MustParse("2k")}, CustomItems{}, CustomItems{})
 	assert.Nil(err)
-	err = bandwidthValidate(CustomItems{Ingress: resource.MustParse("2P")})
+	err = validateRange(CustomItems{Ingress: resource.MustParse("2P")}, CustomItems{}, CustomItems{})
 	assert.ErrorIs(err, common.ErrInvalidBandwidthRange)
 }
 
@@ -196,7 +211,7 @@ func TestBandwidthValidateIS(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			err := bandwidthValidateIsReasonable(tc.mix, tc.def, tc.max)
+			err := validateRange(tc.mix, tc.def, tc.max)
 			assert.ErrorIs(err, tc.expected, tc.name)
 		})
 	}
@@ -207,12 +222,13 @@ func TestCustomLimitRangeIsEmpty(t *testing.T) {
 	t.Parallel()
 
 	c := &CustomLimitRange{}
+	v := &CustomLimitRangeValidator{}
 	ctx := context.Background()
-	_, err := c.ValidateCreate(ctx, nil)
+	_, err := v.ValidateCreate(ctx, c)
 	assert.Nil(err)
-	_, err = c.ValidateDelete(ctx, c)
+	_, err = v.ValidateDelete(ctx, c)
 	assert.Nil(err)
-	_, err = c.ValidateUpdate(ctx, c, c)
+	_, err = v.ValidateUpdate(ctx, c, c)
 	assert.Nil(err)
 	d := c.DeepCopy()
 	assert.Equal(c, d)
@@ -225,30 +241,313 @@ func TestCustomLimitRangeNotEmpty(t *testing.T) {
 	c := &CustomLimitRange{
 		Spec: CustomLimitRangeSpec{
 			LRange: LimitRange{
-				Max: CustomItems{
-					Ingress: resource.MustParse("1P"),
-					Egress:  resource.MustParse("10M"),
-				},
-				Min: CustomItems{
-					Ingress: resource.MustParse("1G"),
-					Egress:  resource.MustParse("1k"),
-				},
-				Default: CustomItems{
-					Ingress: resource.MustParse("1T"),
-					Egress:  resource.MustParse("1M"),
+				BandwidthRange: BandwidthRange{
+					Max: CustomItems{
+						Ingress: resource.MustParse("1P"),
+						Egress:  resource.MustParse("10M"),
+					},
+					Min: CustomItems{
+						Ingress: resource.MustParse("1G"),
+						Egress:  resource.MustParse("1k"),
+					},
+					Default: CustomItems{
+						Ingress: resource.MustParse("1T"),
+						Egress:  resource.MustParse("1M"),
+					},
 				},
 			},
 		},
 	}
 
+	v := &CustomLimitRangeValidator{}
 	ctx := context.Background()
-	_, err := c.ValidateCreate(ctx, nil)
+	_, err := v.ValidateCreate(ctx, c)
 	assert.Nil(err)
-	_, err = c.ValidateDelete(ctx, c)
+	_, err = v.ValidateDelete(ctx, c)
 	assert.Nil(err)
-	_, err = c.ValidateUpdate(ctx, c, c)
+	_, err = v.ValidateUpdate(ctx, c, c)
 	assert.Nil(err)
 
 	d := c.DeepCopy()
 	assert.Equal(c, d)
 }
+
+func TestCustomLimitRangeRules(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	v := &CustomLimitRangeValidator{}
+	ctx := context.Background()
+	c := &CustomLimitRange{
+		Spec: CustomLimitRangeSpec{
+			Rules: []LimitRangeRule{
+				{
+					Name:        "database",
+					PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+					BandwidthRange: BandwidthRange{
+						Max:     CustomItems{Ingress: resource.MustParse("10G")},
+						Default: CustomItems{Ingress: resource.MustParse("5G")},
+					},
+				},
+			},
+		},
+	}
+	_, err := v.ValidateCreate(ctx, c)
+	assert.Nil(err)
+
+	c.Spec.Rules[0].Default.Ingress = resource.MustParse("50G")
+	_, err = v.ValidateCreate(ctx, c)
+	assert.NotNil(err)
+
+	c.Spec.Rules[0].Default.Ingress = resource.MustParse("5G")
+	old := c.DeepCopy()
+	c.Spec.Rules[0].PodSelector.MatchExpressions = []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Bogus"}}
+	_, err = v.ValidateUpdate(ctx, old, c)
+	assert.NotNil(err)
+	// an update that leaves the spec unchanged, such as a status update, is not validated again
+	_, err = v.ValidateUpdate(ctx, c, c)
+	assert.Nil(err)
+}
+
+func TestCustomLimitRangeNetworks(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	v := &CustomLimitRangeValidator{}
+	ctx := context.Background()
+	c := &CustomLimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "test-a"},
+		Spec: CustomLimitRangeSpec{
+			Networks: []NetworkLimitRange{
+				{Name: "storage", BandwidthRange: BandwidthRange{Max: CustomItems{Ingress: resource.MustParse("10G")}}},
+				{Name: "infra/data-plane", BandwidthRange: BandwidthRange{Default: CustomItems{Egress: resource.MustParse("1000M")}}},
+			},
+		},
+	}
+	_, err := v.ValidateCreate(ctx, c)
+	assert.Nil(err)
+	assert.Nil(c.Default(ctx, c))
+	assert.Equal("1G", c.Spec.Networks[1].Default.Egress.String())
+
+	p := c.Policy()
+	assert.Len(p.Networks, 2)
+	assert.Equal("test-a/storage", p.Networks[0].Network)
+	assert.Equal("infra/data-plane", p.Networks[1].Network)
+	ingress := p.Networks[0].Range.Get(common.IngressBandwidthAnnotation)
+	assert.Equal("10G", ingress.Max.String())
+
+	c.Spec.Networks = append(c.Spec.Networks,
+		NetworkLimitRange{Name: "test-a/storage"},
+		NetworkLimitRange{Name: "Storage_Net", BandwidthRange: BandwidthRange{Min: CustomItems{Ingress: resource.MustParse("1G")}, Max: CustomItems{Ingress: resource.MustParse("1M")}}})
+	_, err = v.ValidateCreate(ctx, c)
+	var status *apierrors.StatusError
+	assert.ErrorAs(err, &status)
+	fields := []string{}
+	for _, cause := range status.ErrStatus.Details.Causes {
+		fields = append(fields, cause.Field)
+	}
+	assert.ElementsMatch([]string{
+		"spec.networks[2].name",
+		"spec.networks[3].name",
+		"spec.networks[3].min.ingress-bandwidth",
+	}, fields)
+}
+
+func TestCustomLimitRangeEnforcementMode(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	v := &CustomLimitRangeValidator{}
+	ctx := context.Background()
+	for _, mode := range []EnforcementMode{"", EnforcementModeEnforce, EnforcementModeClamp, EnforcementModeWarn, EnforcementModeAudit, EnforcementModeDryRun} {
+		_, err := v.ValidateCreate(ctx, &CustomLimitRange{Spec: CustomLimitRangeSpec{EnforcementMode: mode}})
+		assert.Nil(err, mode)
+	}
+	_, err := v.ValidateCreate(ctx, &CustomLimitRange{Spec: CustomLimitRangeSpec{EnforcementMode: "block"}})
+	assert.NotNil(err)
+}
+
+func TestCustomLimitRangeBurst(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	v := &CustomLimitRangeValidator{}
+	ctx := context.Background()
+	newBurst := func(max, min, def CustomItems) *CustomLimitRange {
+		return &CustomLimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: "burst", Namespace: "test-a"},
+			Spec: CustomLimitRangeSpec{
+				LRange:   LimitRange{Type: "Pod"},
+				Networks: []NetworkLimitRange{{Name: "storage", BandwidthRange: BandwidthRange{Max: max, Min: min, Default: def}}},
+			},
+		}
+	}
+
+	cases := []struct {
+		name          string
+		max, min, def CustomItems
+		valid         bool
+	}{
+		{
+			name:  "burst range",
+			max:   CustomItems{Ingress: resource.MustParse("1G"), IngressBurst: resource.MustParse("100M")},
+			min:   CustomItems{IngressBurst: resource.MustParse("1M")},
+			def:   CustomItems{Ingress: resource.MustParse("100M"), IngressBurst: resource.MustParse("10M")},
+			valid: true,
+		},
+		{
+			name: "default burst above max burst",
+			max:  CustomItems{EgressBurst: resource.MustParse("10M")},
+			def:  CustomItems{EgressBurst: resource.MustParse("100M")},
+		},
+		{
+			name: "burst too small for the rate",
+			def:  CustomItems{Egress: resource.MustParse("10G"), EgressBurst: resource.MustParse("1M")},
+		},
+		{
+			name: "burst unreasonably small",
+			max:  CustomItems{IngressBurst: resource.MustParse("10")},
+		},
+	}
+	for _, c := range cases {
+		_, err := v.ValidateCreate(ctx, newBurst(c.max, c.min, c.def))
+		assert.Equal(c.valid, err == nil, c.name)
+	}
+
+	b := policy.Merge(policy.ForNetwork(Policies([]CustomLimitRange{
+		*newBurst(CustomItems{IngressBurst: resource.MustParse("100M")}, CustomItems{}, CustomItems{IngressBurst: resource.MustParse("50M")}),
+		*newBurst(CustomItems{IngressBurst: resource.MustParse("20M")}, CustomItems{}, CustomItems{}),
+	}), "test-a/storage"), nil).Range.Get(common.IngressBurstKey)
+	assert.Equal("20M", b.Max.String())
+	assert.Equal("20M", b.Default.String())
+
+	// the container runtimes hard-code the burst of the pod network
+	r := newBurst(CustomItems{}, CustomItems{}, CustomItems{})
+	r.Spec.LRange.Max.IngressBurst = resource.MustParse("100M")
+	r.Spec.Rules = []LimitRangeRule{{Name: "db", BandwidthRange: BandwidthRange{Default: CustomItems{EgressBurst: resource.MustParse("10M")}}}}
+	_, err := v.ValidateCreate(ctx, r)
+	assert.ErrorContains(err, "spec.limitrange.max.ingress-burst: Forbidden: bursts are only supported in spec.networks")
+	assert.ErrorContains(err, "spec.rules[0].default.egress-burst: Forbidden")
+	err = (&ClusterCustomLimitRange{Spec: ClusterCustomLimitRangeSpec{LRange: r.Spec.LRange}}).validate()
+	assert.ErrorContains(err, "spec.limitrange.max.ingress-burst: Forbidden")
+}
+
+func TestCustomLimitRangeFieldErrors(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	v := &CustomLimitRangeValidator{}
+	ctx := context.Background()
+	r := &CustomLimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "invalid", Namespace: "test-a"},
+		Spec: CustomLimitRangeSpec{
+			LRange: LimitRange{
+				Type: "Pod",
+				BandwidthRange: BandwidthRange{
+					Max:     CustomItems{Ingress: resource.MustParse("2P"), Egress: resource.MustParse("100M")},
+					Default: CustomItems{Egress: resource.MustParse("1G")},
+				},
+			},
+			Rules: []LimitRangeRule{{
+				Name: "db",
+				BandwidthRange: BandwidthRange{
+					Min: CustomItems{Ingress: resource.MustParse("10M")},
+					Max: CustomItems{Ingress: resource.MustParse("1M")},
+				},
+			}},
+			EnforcementMode: "block",
+		},
+	}
+
+	_, err := v.ValidateCreate(ctx, r)
+	var status *apierrors.StatusError
+	assert.ErrorAs(err, &status)
+	fields := []string{}
+	for _, cause := range status.ErrStatus.Details.Causes {
+		fields = append(fields, cause.Field)
+	}
+	assert.ElementsMatch([]string{
+		"spec.limitrange.max.ingress-bandwidth",
+		"spec.limitrange.default.egress-bandwidth",
+		"spec.enforcementMode",
+		"spec.rules[0].min.ingress-bandwidth",
+	}, fields)
+
+	old := r.DeepCopy()
+	old.Spec.Priority = 1
+	_, err = v.ValidateUpdate(ctx, old, r)
+	assert.ErrorAs(err, &status)
+	assert.Len(status.ErrStatus.Details.Causes, 4)
+}
+
+func TestCustomLimitRangeDefault(t *testing.T) {
+	assert := assert.New(t)
+	t.Parallel()
+
+	c := &CustomLimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test-a"},
+		Spec: CustomLimitRangeSpec{
+			LRange: LimitRange{
+				Type: "POD",
+				BandwidthRange: BandwidthRange{
+					Max: CustomItems{Ingress: resource.MustParse("1000M"), Egress: resource.MustParse("2000000k")},
+					Min: CustomItems{Ingress: resource.MustParse("10M"), Egress: resource.MustParse("1000k")},
+					// an explicit default is kept
+					Default: CustomItems{Egress: resource.MustParse("500M")},
+				},
+			},
+			Rules:         []LimitRangeRule{{Name: "db", BandwidthRange: BandwidthRange{Min: CustomItems{Ingress: resource.MustParse("1G")}}}},
+			DefaultPolicy: DefaultPolicyMin,
+		},
+	}
+	ctx := context.Background()
+	assert.Nil(c.Default(ctx, c))
+	assert.Equal(LimitRangeTypePod, c.Spec.LRange.Type)
+	assert.Equal("1G", c.Spec.LRange.Max.Ingress.String())
+	assert.Equal("2G", c.Spec.LRange.Max.Egress.String())
+	assert.Equal("1M", c.Spec.LRange.Min.Egress.String())
+	assert.Equal("10M", c.Spec.LRange.Default.Ingress.String())
+	assert.Equal("500M", c.Spec.LRange.Default.Egress.String())
+	assert.Equal("1G", c.Spec.Rules[0].Default.Ingress.String())
+	assert.Equal("spec.limitrange.default.ingress-bandwidth,spec.rules[0].default.ingress-bandwidth",
+		c.Annotations[common.DefaultedAnnotation])
+
+	// unset quantities are not serialized
+	b, err := json.Marshal(c.Spec.Rules[0].Max)
+	assert.Nil(err)
+	assert.Equal("{}", string(b))
+
+	// on update, a recorded default follows its min unless it was changed
+	old := c.DeepCopy()
+	c.Spec.LRange.Min.Ingress = resource.MustParse("20M")
+	c.Spec.Rules[0].Default.Ingress = resource.MustParse("2G")
+	// bursts of the pod network stored by older versions are dropped
+	c.Spec.LRange.Max.IngressBurst = resource.MustParse("10M")
+	c.Spec.Rules[0].Min.EgressBurst = resource.MustParse("1M")
+	raw, _ := json.Marshal(old)
+	ctx = admission.NewContextWithRequest(ctx, admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: admissionv1.Update,
+		OldObject: runtime.RawExtension{Raw: raw},
+	}})
+	assert.Nil(c.Default(ctx, c))
+	assert.Equal("20M", c.Spec.LRange.Default.Ingress.String())
+	assert.Equal("2G", c.Spec.Rules[0].Default.Ingress.String())
+	assert.Equal("spec.limitrange.default.ingress-bandwidth", c.Annotations[common.DefaultedAnnotation])
+	assert.True(c.Spec.LRange.Max.IngressBurst.IsZero())
+	assert.True(c.Spec.Rules[0].Min.EgressBurst.IsZero())
+
+	// without a policy, nothing is filled
+	c = &CustomLimitRange{Spec: CustomLimitRangeSpec{LRange: LimitRange{
+		Type:           "pod",
+		BandwidthRange: BandwidthRange{Min: CustomItems{Ingress: resource.MustParse("10M")}},
+	}}}
+	assert.Nil(c.Default(context.Background(), c))
+	assert.Equal(LimitRangeTypePod, c.Spec.LRange.Type)
+	assert.True(c.Spec.LRange.Default.Ingress.IsZero())
+	assert.NotContains(c.Annotations, common.DefaultedAnnotation)
+
+	c.Spec.DefaultPolicy = "median"
+	_, err = (&CustomLimitRangeValidator{}).ValidateCreate(context.Background(), c)
+	assert.True(apierrors.IsInvalid(err))
+	assert.ErrorContains(err, "spec.defaultPolicy")
+}
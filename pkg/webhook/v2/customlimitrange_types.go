@@ -0,0 +1,202 @@
+/*
+Copyright 2022 The KubeService-Stack Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is group version used to register these objects
+	GroupVersion = schema.GroupVersion{Group: "custom.cmss.com", Version: "v2"}
+
+	// SchemeGroupVersion is GroupVersion under the name the generated clientset expects.
+	SchemeGroupVersion = GroupVersion
+
+	// SchemeBuilder is used to add go types to the GroupVersionKind scheme
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the types in this group-version to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
+
+// Resource takes an unqualified resource and returns a Group qualified GroupResource.
+func Resource(resource string) schema.GroupResource {
+	return SchemeGroupVersion.WithResource(resource).GroupResource()
+}
+
+// +kubebuilder:validation:XValidation:rule="!has(self.rate) || !has(self.burst) || quantity(string(self.burst)).asApproximateFloat() * 1000.0 >= quantity(string(self.rate)).asApproximateFloat()",message="burst must hold at least 1ms of traffic at the rate (burst >= rate/1000)"
+
+// Bandwidth is the rate and the token bucket size, in bits, of one direction of pod traffic. The
+// burst only bounds Multus networks, in spec.networks: the container runtimes hard-code the burst
+// of the pod network.
+type Bandwidth struct {
+	// +optional
+	// +kubebuilder:validation:XIntOrString
+	// +kubebuilder:validation:MaxLength=32
+	// +kubebuilder:validation:XValidation:rule="quantity(string(self)).compareTo(quantity('1k')) >= 0 && quantity(string(self)).compareTo(quantity('1P')) <= 0",message="resource is unreasonably small (< 1kbit) or large (> 1Pbit)"
+	Rate resource.Quantity `json:"rate,omitzero"`
+	// +optional
+	// +kubebuilder:validation:XIntOrString
+	// +kubebuilder:validation:MaxLength=32
+	// +kubebuilder:validation:XValidation:rule="quantity(string(self)).compareTo(quantity('1k')) >= 0 && quantity(string(self)).compareTo(quantity('1P')) <= 0",message="resource is unreasonably small (< 1kbit) or large (> 1Pbit)"
+	Burst resource.Quantity `json:"burst,omitzero"`
+}
+
+// Limits holds one bound of the pod bandwidth in each direction.
+type Limits struct {
+	// +optional
+	Ingress Bandwidth `json:"ingress,omitzero"`
+	// +optional
+	Egress Bandwidth `json:"egress,omitzero"`
+}
+
+// EnforcementMode controls what happens to pods whose bandwidth is out of range.
+// +kubebuilder:validation:Enum=enforce;clamp;warn;audit;dryRun
+type EnforcementMode string
+
+// DefaultPolicy fills the unset defaults of a range from its other bounds.
+// +kubebuilder:validation:Enum=none;min;max
+type DefaultPolicy string
+
+// BandwidthRange is the max, min and default of a bandwidth range. Its validation rules require
+// min <= default <= max.
+// +kubebuilder:validation:XValidation:rule="!self.?min.?ingress.?rate.hasValue() || !self.?max.?ingress.?rate.hasValue() || quantity(string(self.min.ingress.rate)).compareTo(quantity(string(self.max.ingress.rate))) <= 0",message="min.ingress.rate must be less than or equal to max.ingress.rate"
+// +kubebuilder:validation:XValidation:rule="!self.?min.?ingress.?rate.hasValue() || !self.?default.?ingress.?rate.hasValue() || quantity(string(self.min.ingress.rate)).compareTo(quantity(string(self.default.ingress.rate))) <= 0",message="default.ingress.rate must be greater than or equal to min.ingress.rate"
+// +kubebuilder:validation:XValidation:rule="!self.?default.?ingress.?rate.hasValue() || !self.?max.?ingress.?rate.hasValue() || quantity(string(self.default.ingress.rate)).compareTo(quantity(string(self.max.ingress.rate))) <= 0",message="default.ingress.rate must be less than or equal to max.ingress.rate"
+// +kubebuilder:validation:XValidation:rule="!self.?min.?ingress.?burst.hasValue() || !self.?max.?ingress.?burst.hasValue() || quantity(string(self.min.ingress.burst)).compareTo(quantity(string(self.max.ingress.burst))) <= 0",message="min.ingress.burst must be less than or equal to max.ingress.burst"
+// +kubebuilder:validation:XValidation:rule="!self.?min.?ingress.?burst.hasValue() || !self.?default.?ingress.?burst.hasValue() || quantity(string(self.min.ingress.burst)).compareTo(quantity(string(self.default.ingress.burst))) <= 0",message="default.ingress.burst must be greater than or equal to min.ingress.burst"
+// +kubebuilder:validation:XValidation:rule="!self.?default.?ingress.?burst.hasValue() || !self.?max.?ingress.?burst.hasValue() || quantity(string(self.default.ingress.burst)).compareTo(quantity(string(self.max.ingress.burst))) <= 0",message="default.ingress.burst must be less than or equal to max.ingress.burst"
+// +kubebuilder:validation:XValidation:rule="!self.?min.?egress.?rate.hasValue() || !self.?max.?egress.?rate.hasValue() || quantity(string(self.min.egress.rate)).compareTo(quantity(string(self.max.egress.rate))) <= 0",message="min.egress.rate must be less than or equal to max.egress.rate"
+// +kubebuilder:validation:XValidation:rule="!self.?min.?egress.?rate.hasValue() || !self.?default.?egress.?rate.hasValue() || quantity(string(self.min.egress.rate)).compareTo(quantity(string(self.default.egress.rate))) <= 0",message="default.egress.rate must be greater than or equal to min.egress.rate"
+// +kubebuilder:validation:XValidation:rule="!self.?default.?egress.?rate.hasValue() || !self.?max.?egress.?rate.hasValue() || quantity(string(self.default.egress.rate)).compareTo(quantity(string(self.max.egress.rate))) <= 0",message="default.egress.rate must be less than or equal to max.egress.rate"
+// +kubebuilder:validation:XValidation:rule="!self.?min.?egress.?burst.hasValue() || !self.?max.?egress.?burst.hasValue() || quantity(string(self.min.egress.burst)).compareTo(quantity(string(self.max.egress.burst))) <= 0",message="min.egress.burst must be less than or equal to max.egress.burst"
+// +kubebuilder:validation:XValidation:rule="!self.?min.?egress.?burst.hasValue() || !self.?default.?egress.?burst.hasValue() || quantity(string(self.min.egress.burst)).compareTo(quantity(string(self.default.egress.burst))) <= 0",message="default.egress.burst must be greater than or equal to min.egress.burst"
+// +kubebuilder:validation:XValidation:rule="!self.?default.?egress.?burst.hasValue() || !self.?max.?egress.?burst.hasValue() || quantity(string(self.default.egress.burst)).compareTo(quantity(string(self.max.egress.burst))) <= 0",message="default.egress.burst must be less than or equal to max.egress.burst"
+type BandwidthRange struct {
+	// +optional
+	Max Limits `json:"max,omitzero"`
+	// +optional
+	Min Limits `json:"min,omitzero"`
+	// +optional
+	Default Limits `json:"default,omitzero"`
+}
+
+// LimitRangeRule applies its own range to the pods selected by PodSelector. It takes no bursts.
+// +kubebuilder:validation:XValidation:rule="!self.?max.?ingress.?burst.hasValue() && !self.?max.?egress.?burst.hasValue() && !self.?min.?ingress.?burst.hasValue() && !self.?min.?egress.?burst.hasValue() && !self.?default.?ingress.?burst.hasValue() && !self.?default.?egress.?burst.hasValue()",message="bursts are only supported in spec.networks, the container runtimes do not shape the burst of the pod network"
+type LimitRangeRule struct {
+	Name string `json:"name,omitempty"`
+	// PodSelector selects the pods the rule applies to. A nil selector selects every pod.
+	PodSelector    *metav1.LabelSelector `json:"podSelector,omitempty"`
+	BandwidthRange `json:",inline"`
+}
+
+// NetworkLimitRange bounds the bandwidth of the interfaces that pods attach to a Multus secondary
+// network, in the bandwidth of their network selection in the k8s.v1.cni.cncf.io/networks annotation.
+type NetworkLimitRange struct {
+	// Name is the NetworkAttachmentDefinition, as namespace/name, or as name in the namespace of
+	// the CustomLimitRange.
+	// +kubebuilder:validation:MinLength=1
+	Name           string `json:"name"`
+	BandwidthRange `json:",inline"`
+}
+
+// CustomLimitRangeSpec defines the desired state of CustomLimitRange.
+// Its BandwidthRange is the catch-all range for pods not selected by any rule, which takes no bursts.
+// +kubebuilder:validation:XValidation:rule="!self.?max.?ingress.?burst.hasValue() && !self.?max.?egress.?burst.hasValue() && !self.?min.?ingress.?burst.hasValue() && !self.?min.?egress.?burst.hasValue() && !self.?default.?ingress.?burst.hasValue() && !self.?default.?egress.?burst.hasValue()",message="bursts are only supported in spec.networks, the container runtimes do not shape the burst of the pod network"
+type CustomLimitRangeSpec struct {
+	BandwidthRange `json:",inline"`
+	// Rules are evaluated in order; the first rule selecting a pod replaces the catch-all range.
+	// +kubebuilder:validation:MaxItems=64
+	Rules []LimitRangeRule `json:"rules,omitempty"`
+	// Networks bound the bandwidth of the interfaces of Multus secondary networks, by
+	// NetworkAttachmentDefinition. The catch-all range and the rules bound the cluster network.
+	// +kubebuilder:validation:MaxItems=32
+	// +listType=map
+	// +listMapKey=name
+	Networks []NetworkLimitRange `json:"networks,omitempty"`
+	// EnforcementMode is one of enforce (default), clamp, warn, audit or dryRun.
+	EnforcementMode EnforcementMode `json:"enforcementMode,omitempty"`
+	// Priority orders the defaults of the CustomLimitRanges of a namespace, which may hold several of
+	// them; the highest priority wins, and two of them may not share a priority.
+	Priority int32 `json:"priority,omitempty"`
+	// DefaultPolicy is one of none (default), min or max. Defaults it fills are listed in the
+	// customlimitrange.kubernetes.io/defaulted annotation.
+	DefaultPolicy DefaultPolicy `json:"defaultPolicy,omitempty"`
+}
+
+// CustomLimitRangeStatus defines the observed state of CustomLimitRange
+type CustomLimitRangeStatus struct {
+	// ObservedGeneration is the most recent generation evaluated by the controller.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions describe the current state of the policy (Ready, Conflicting).
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// CompliantPods is the number of pods whose bandwidth annotations are within range.
+	CompliantPods int32 `json:"compliantPods"`
+	// DefaultedPods is the number of pods carrying the policy default bandwidth.
+	DefaultedPods int32 `json:"defaultedPods"`
+	// OutOfRangePods is the number of pods whose bandwidth annotations violate the range.
+	OutOfRangePods int32 `json:"outOfRangePods"`
+	// LastEvaluationTime is the last time the controller evaluated the pods in the namespace.
+	LastEvaluationTime *metav1.Time `json:"lastEvaluationTime,omitempty"`
+}
+
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:resource:shortName=clr
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.spec.enforcementMode`
+// +kubebuilder:printcolumn:name="Priority",type=integer,JSONPath=`.spec.priority`
+// +kubebuilder:printcolumn:name="Conflicting",type=string,JSONPath=`.status.conditions[?(@.type=="Conflicting")].status`
+// +kubebuilder:printcolumn:name="Compliant",type=integer,JSONPath=`.status.compliantPods`
+// +kubebuilder:printcolumn:name="Defaulted",type=integer,JSONPath=`.status.defaultedPods`
+// +kubebuilder:printcolumn:name="OutOfRange",type=integer,JSONPath=`.status.outOfRangePods`
+// +kubebuilder:printcolumn:name="LastEvaluated",type=date,JSONPath=`.status.lastEvaluationTime`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// CustomLimitRange is the Schema for the customlimitranges API.
+// It is the storage version, and the hub the other versions are converted through.
+type CustomLimitRange struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CustomLimitRangeSpec   `json:"spec"`
+	Status CustomLimitRangeStatus `json:"status,omitempty"`
+}
+
+// Hub marks this type as a conversion hub.
+func (*CustomLimitRange) Hub() {}
+
+// +kubebuilder:object:root=true
+
+// CustomLimitRangeList contains a list of CustomLimitRange
+type CustomLimitRangeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CustomLimitRange `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CustomLimitRange{}, &CustomLimitRangeList{})
+}
@@ -22,14 +22,223 @@ limitations under the License.
 package webhook
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BandwidthItems) DeepCopyInto(out *BandwidthItems) {
+	*out = *in
+	out.Ingress = in.Ingress.DeepCopy()
+	out.Egress = in.Egress.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BandwidthItems.
+func (in *BandwidthItems) DeepCopy() *BandwidthItems {
+	if in == nil {
+		return nil
+	}
+	out := new(BandwidthItems)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BandwidthQuota) DeepCopyInto(out *BandwidthQuota) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BandwidthQuota.
+func (in *BandwidthQuota) DeepCopy() *BandwidthQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(BandwidthQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BandwidthQuota) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BandwidthQuotaList) DeepCopyInto(out *BandwidthQuotaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BandwidthQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BandwidthQuotaList.
+func (in *BandwidthQuotaList) DeepCopy() *BandwidthQuotaList {
+	if in == nil {
+		return nil
+	}
+	out := new(BandwidthQuotaList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BandwidthQuotaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BandwidthQuotaSpec) DeepCopyInto(out *BandwidthQuotaSpec) {
+	*out = *in
+	in.Hard.DeepCopyInto(&out.Hard)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BandwidthQuotaSpec.
+func (in *BandwidthQuotaSpec) DeepCopy() *BandwidthQuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BandwidthQuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BandwidthQuotaStatus) DeepCopyInto(out *BandwidthQuotaStatus) {
+	*out = *in
+	in.Hard.DeepCopyInto(&out.Hard)
+	in.Used.DeepCopyInto(&out.Used)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BandwidthQuotaStatus.
+func (in *BandwidthQuotaStatus) DeepCopy() *BandwidthQuotaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BandwidthQuotaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BandwidthRange) DeepCopyInto(out *BandwidthRange) {
+	*out = *in
+	in.Max.DeepCopyInto(&out.Max)
+	in.Min.DeepCopyInto(&out.Min)
+	in.Default.DeepCopyInto(&out.Default)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BandwidthRange.
+func (in *BandwidthRange) DeepCopy() *BandwidthRange {
+	if in == nil {
+		return nil
+	}
+	out := new(BandwidthRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterCustomLimitRange) DeepCopyInto(out *ClusterCustomLimitRange) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterCustomLimitRange.
+func (in *ClusterCustomLimitRange) DeepCopy() *ClusterCustomLimitRange {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterCustomLimitRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterCustomLimitRange) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterCustomLimitRangeList) DeepCopyInto(out *ClusterCustomLimitRangeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterCustomLimitRange, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterCustomLimitRangeList.
+func (in *ClusterCustomLimitRangeList) DeepCopy() *ClusterCustomLimitRangeList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterCustomLimitRangeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterCustomLimitRangeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterCustomLimitRangeSpec) DeepCopyInto(out *ClusterCustomLimitRangeSpec) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.LRange.DeepCopyInto(&out.LRange)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterCustomLimitRangeSpec.
+func (in *ClusterCustomLimitRangeSpec) DeepCopy() *ClusterCustomLimitRangeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterCustomLimitRangeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CustomItems) DeepCopyInto(out *CustomItems) {
 	*out = *in
 	out.Ingress = in.Ingress.DeepCopy()
 	out.Egress = in.Egress.DeepCopy()
+	out.IngressBurst = in.IngressBurst.DeepCopy()
+	out.EgressBurst = in.EgressBurst.DeepCopy()
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomItems.
@@ -48,7 +257,7 @@ func (in *CustomLimitRange) DeepCopyInto(out *CustomLimitRange) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomLimitRange.
@@ -105,6 +314,20 @@ func (in *CustomLimitRangeList) DeepCopyObject() runtime.Object {
 func (in *CustomLimitRangeSpec) DeepCopyInto(out *CustomLimitRangeSpec) {
 	*out = *in
 	in.LRange.DeepCopyInto(&out.LRange)
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]LimitRangeRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Networks != nil {
+		in, out := &in.Networks, &out.Networks
+		*out = make([]NetworkLimitRange, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomLimitRangeSpec.
@@ -120,6 +343,17 @@ func (in *CustomLimitRangeSpec) DeepCopy() *CustomLimitRangeSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CustomLimitRangeStatus) DeepCopyInto(out *CustomLimitRangeStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastEvaluationTime != nil {
+		in, out := &in.LastEvaluationTime, &out.LastEvaluationTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomLimitRangeStatus.
@@ -135,9 +369,7 @@ func (in *CustomLimitRangeStatus) DeepCopy() *CustomLimitRangeStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LimitRange) DeepCopyInto(out *LimitRange) {
 	*out = *in
-	in.Max.DeepCopyInto(&out.Max)
-	in.Min.DeepCopyInto(&out.Min)
-	in.Default.DeepCopyInto(&out.Default)
+	in.BandwidthRange.DeepCopyInto(&out.BandwidthRange)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LimitRange.
@@ -149,3 +381,40 @@ func (in *LimitRange) DeepCopy() *LimitRange {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LimitRangeRule) DeepCopyInto(out *LimitRangeRule) {
+	*out = *in
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.BandwidthRange.DeepCopyInto(&out.BandwidthRange)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LimitRangeRule.
+func (in *LimitRangeRule) DeepCopy() *LimitRangeRule {
+	if in == nil {
+		return nil
+	}
+	out := new(LimitRangeRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkLimitRange) DeepCopyInto(out *NetworkLimitRange) {
+	*out = *in
+	in.BandwidthRange.DeepCopyInto(&out.BandwidthRange)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkLimitRange.
+func (in *NetworkLimitRange) DeepCopy() *NetworkLimitRange {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkLimitRange)
+	in.DeepCopyInto(out)
+	return out
+}